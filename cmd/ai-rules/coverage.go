@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/config"
+	"github.com/cristiano-pacheco/ai-rules/internal/coverage"
+	"github.com/cristiano-pacheco/ai-rules/internal/gomod"
+)
+
+func runCoverage(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("coverage", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	profilePath := flags.String("profile", "coverage.out", "coverage profile written by go test -coverprofile")
+	moduleDir := flags.String("module", ".", "root of the Go module the profile was produced from")
+	configPath := flags.String("config", "", "path to ai-rules.yaml (default <module>/ai-rules.yaml)")
+	format := flags.String("format", "text", "output format: text or json")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: ai-rules coverage [flags]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Checks per-package statement coverage against ai-rules.yaml and lists exported functions")
+		fmt.Fprintln(stderr, "without coverage. Generated files are excluded. Exits 1 when the policy fails.")
+		fmt.Fprintln(stderr)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "ai-rules coverage: unknown format %q\n", *format)
+		return exitUsage
+	}
+
+	cfg, err := loadConfig(*moduleDir, *configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules coverage: %v\n", err)
+		return exitUsage
+	}
+	report, err := evaluateCoverage(cfg, *moduleDir, *profilePath)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules coverage: %v\n", err)
+		return exitUsage
+	}
+
+	if *format == "json" {
+		err = report.WriteJSON(stdout)
+	} else {
+		err = report.WriteText(stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules coverage: %v\n", err)
+		return exitUsage
+	}
+	if !report.Passed() {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// loadConfig reads an explicit config path, or <module>/ai-rules.yaml when present, or falls back to defaults.
+func loadConfig(moduleDir, configPath string) (config.Config, error) {
+	explicit := configPath != ""
+	if !explicit {
+		configPath = filepath.Join(moduleDir, config.FileName)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil && !explicit && errors.Is(err, fs.ErrNotExist) {
+		return config.Default(), nil
+	}
+	return cfg, err
+}
+
+func evaluateCoverage(cfg config.Config, moduleDir, profilePath string) (*coverage.Report, error) {
+	policy, blocks, err := openCoverage(cfg, moduleDir, profilePath)
+	if err != nil {
+		return nil, err
+	}
+	return policy.Evaluate(blocks)
+}
+
+// openCoverage reads the profile and builds the policy of cfg for the module it was produced from.
+func openCoverage(cfg config.Config, moduleDir, profilePath string) (*coverage.Policy, []coverage.Block, error) {
+	module, err := gomod.NewModule(moduleDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(profilePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open profile: %w", err)
+	}
+	defer f.Close()
+
+	blocks, err := coverage.ParseProfile(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	policy, err := coverage.NewPolicy(cfg.Coverage, module)
+	if err != nil {
+		return nil, nil, err
+	}
+	return policy, blocks, nil
+}
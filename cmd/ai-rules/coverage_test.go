@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes files, keyed by slash-separated path, under dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRunCoverage_Profiles_ExitWithPolicyResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		count    string
+		wantCode int
+		wantOut  string
+	}{
+		{name: "covered package passes", count: "1", wantCode: exitOK, wantOut: "ok"},
+		{name: "uncovered package fails", count: "0", wantCode: exitFailed, wantOut: "FAIL"},
+		{
+			name:     "package override passes",
+			config:   "coverage:\n  packages:\n    p: 0\n",
+			count:    "0",
+			wantCode: exitOK,
+			wantOut:  "ok",
+		},
+		{
+			name:     "invalid config is a usage error",
+			config:   "coverage:\n  threshold: 200\n",
+			count:    "1",
+			wantCode: exitUsage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			files := map[string]string{
+				"go.mod":       "module example.com/m\n\ngo 1.24\n",
+				"p/p.go":       "package p\n\nfunc Do() int {\n\treturn 1\n}\n",
+				"coverage.out": "mode: set\nexample.com/m/p/p.go:3.16,5.2 1 " + tt.count + "\n",
+			}
+			if tt.config != "" {
+				files["ai-rules.yaml"] = tt.config
+			}
+			writeFiles(t, dir, files)
+			var stdout, stderr bytes.Buffer
+
+			// Act
+			code := run([]string{"coverage", "-module", dir, "-profile", filepath.Join(dir, "coverage.out")},
+				&stdout, &stderr)
+
+			// Assert
+			if code != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d; stderr: %s", code, tt.wantCode, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.wantOut) {
+				t.Errorf("stdout = %q, want it to contain %q", stdout.String(), tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestRun_BadArguments_ReturnsUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "no command", args: nil},
+		{name: "unknown command", args: []string{"covrage"}},
+		{name: "unknown flag", args: []string{"coverage", "-nope"}},
+		{name: "unknown format", args: []string{"coverage", "-format", "xml"}},
+		{name: "missing profile", args: []string{"coverage", "-module", ".", "-profile", "missing.out"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var stdout, stderr bytes.Buffer
+
+			// Act
+			code := run(tt.args, &stdout, &stderr)
+
+			// Assert
+			if code != exitUsage {
+				t.Errorf("exit code = %d, want %d", code, exitUsage)
+			}
+		})
+	}
+}
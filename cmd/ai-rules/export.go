@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+
+	airules "github.com/cristiano-pacheco/ai-rules"
+	"github.com/cristiano-pacheco/ai-rules/internal/config"
+	"github.com/cristiano-pacheco/ai-rules/internal/export"
+)
+
+func runExport(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dir := flags.String("dir", ".claude/skills", "directory the skills are written to, one subdirectory per skill")
+	moduleDir := flags.String("module", ".", "root of the Go module whose ai-rules.yaml selects the flavor")
+	configPath := flags.String("config", "", "path to ai-rules.yaml (default <module>/ai-rules.yaml)")
+	flavorUsage := "unit test flavor, overriding unit_tests.flavor: " + strings.Join(config.Flavors, ", ")
+	flavor := flags.String("flavor", "", flavorUsage)
+	list := flags.Bool("list", false, "print the skills that would be installed and write nothing")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: ai-rules export [flags]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Installs the skills into a project. Only the go-unit-tests variant for the configured unit")
+		fmt.Fprintln(stderr, "test flavor is installed; variants of other flavors already in the directory are removed.")
+		fmt.Fprintln(stderr)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	cfg, err := loadConfig(*moduleDir, *configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules export: %v\n", err)
+		return exitUsage
+	}
+	if *flavor != "" {
+		if !slices.Contains(config.Flavors, *flavor) {
+			fmt.Fprintf(stderr, "ai-rules export: unknown flavor %q\n", *flavor)
+			return exitUsage
+		}
+		cfg.UnitTests.Flavor = *flavor
+	}
+
+	skills, err := fs.Sub(airules.Skills, "skills")
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules export: %v\n", err)
+		return exitUsage
+	}
+	exporter := export.NewExporter(skills, cfg.UnitTests.Flavor)
+
+	if *list {
+		names, err := exporter.Skills()
+		if err != nil {
+			fmt.Fprintf(stderr, "ai-rules export: %v\n", err)
+			return exitUsage
+		}
+		for _, name := range names {
+			fmt.Fprintln(stdout, name)
+		}
+		return exitOK
+	}
+
+	result, err := exporter.Export(*dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules export: %v\n", err)
+		return exitUsage
+	}
+	fmt.Fprintf(stdout, "installed %d skills in %s (unit test flavor: %s)\n",
+		len(result.Installed), *dir, cfg.UnitTests.Flavor)
+	for _, name := range result.Removed {
+		fmt.Fprintf(stdout, "removed %s (other unit test flavor)\n", name)
+	}
+	return exitOK
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+)
+
+func runGaps(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gaps", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	profilePath := flags.String("profile", "coverage.out", "coverage profile written by go test -coverprofile")
+	moduleDir := flags.String("module", ".", "root of the Go module the profile was produced from")
+	configPath := flags.String("config", "", "path to ai-rules.yaml (default <module>/ai-rules.yaml)")
+	format := flags.String("format", "text", "output format: text or json")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: ai-rules gaps [flags]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Lists exported functions no test calls and error branches no test takes, each with the")
+		fmt.Fprintln(stderr, "rule it breaks and the scaffold command that generates the missing test. Exits 1 when any")
+		fmt.Fprintln(stderr, "gap is found.")
+		fmt.Fprintln(stderr)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "ai-rules gaps: unknown format %q\n", *format)
+		return exitUsage
+	}
+
+	cfg, err := loadConfig(*moduleDir, *configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules gaps: %v\n", err)
+		return exitUsage
+	}
+	policy, blocks, err := openCoverage(cfg, *moduleDir, *profilePath)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules gaps: %v\n", err)
+		return exitUsage
+	}
+	report, err := policy.Gaps(blocks)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules gaps: %v\n", err)
+		return exitUsage
+	}
+
+	if *format == "json" {
+		err = report.WriteJSON(stdout)
+	} else {
+		err = report.WriteText(stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules gaps: %v\n", err)
+		return exitUsage
+	}
+	if len(report.Gaps) > 0 {
+		return exitFailed
+	}
+	return exitOK
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// gapsModule is a module whose tests run nothing: a root package, and a package with a type, its
+// constructor, a function, and generic declarations scaffold cannot generate tests for.
+var gapsModule = map[string]string{
+	"go.mod": "module example.com/m\n\ngo 1.24\n",
+	"root.go": `package m
+
+func Root() int {
+	return 1
+}
+`,
+	"p/p.go": `package p
+
+import "errors"
+
+type Service struct{}
+
+func NewService() *Service {
+	return &Service{}
+}
+
+func (s *Service) Do() error {
+	return errors.New("failed")
+}
+
+func Sum(a, b int) int {
+	return a + b
+}
+
+func Map[T any](items []T) []T {
+	return items
+}
+
+type Box[T any] struct {
+	v T
+}
+
+func (b Box[T]) Get() T {
+	return b.v
+}
+`,
+}
+
+// writeGapsModule writes gapsModule to a new directory with a profile in which no function body ran.
+func writeGapsModule(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFiles(t, dir, gapsModule)
+	profile := "mode: set\n"
+	for _, rel := range []string{"root.go", "p/p.go"} {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, filepath.Join(dir, rel), nil, parser.SkipObjectResolution)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok {
+				start, end := fset.Position(fn.Body.Lbrace), fset.Position(fn.Body.Rbrace)
+				profile += fmt.Sprintf("example.com/m/%s:%d.%d,%d.%d 1 0\n",
+					rel, start.Line, start.Column+1, end.Line, end.Column+1)
+			}
+		}
+	}
+	writeFiles(t, dir, map[string]string{"coverage.out": profile})
+	return dir
+}
+
+func TestRunGaps_UncoveredFunctions_SuggestScaffoldSubjects(t *testing.T) {
+	// Arrange
+	dir := writeGapsModule(t)
+	var stdout, stderr bytes.Buffer
+
+	// Act
+	code := run([]string{"gaps", "-module", dir, "-profile", filepath.Join(dir, "coverage.out"), "-format", "json"},
+		&stdout, &stderr)
+
+	// Assert
+	if code != exitFailed {
+		t.Fatalf("exit code = %d, want %d; stderr: %s", code, exitFailed, stderr.String())
+	}
+	var report struct {
+		Gaps []struct {
+			Func     string `json:"func"`
+			Scaffold string `json:"scaffold"`
+		} `json:"gaps"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, gap := range report.Gaps {
+		got[gap.Func] = gap.Scaffold
+	}
+	want := map[string]string{
+		"Root":       "ai-rules scaffold -dir . -only Root",
+		"NewService": "ai-rules scaffold -dir p -only Service",
+		"Service.Do": "ai-rules scaffold -dir p -only Service.Do",
+		"Sum":        "ai-rules scaffold -dir p -only Sum",
+		"Map":        "",
+		"Box.Get":    "",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scaffold commands = %v, want %v", got, want)
+	}
+}
+
+func TestRunGaps_SuggestedCommands_Succeed(t *testing.T) {
+	// Arrange
+	dir := writeGapsModule(t)
+	var stdout, stderr bytes.Buffer
+	run([]string{"gaps", "-module", dir, "-profile", filepath.Join(dir, "coverage.out")}, &stdout, &stderr)
+	var commands []string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if cmd, ok := strings.CutPrefix(strings.TrimSpace(line), "scaffold: "); ok {
+			commands = append(commands, cmd)
+		}
+	}
+	if len(commands) == 0 {
+		t.Fatalf("no scaffold commands in:\n%s", stdout.String())
+	}
+
+	for _, command := range commands {
+		t.Run(command, func(t *testing.T) {
+			args := append(strings.Fields(command)[1:], "-module", dir)
+			var stdout, stderr bytes.Buffer
+
+			// Act
+			code := run(args, &stdout, &stderr)
+
+			// Assert
+			if code != exitOK {
+				t.Errorf("exit code = %d, want %d; stderr: %s", code, exitOK, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunGaps_TextReport_EndsWithSummary(t *testing.T) {
+	// Arrange
+	dir := writeGapsModule(t)
+	var stdout, stderr bytes.Buffer
+
+	// Act
+	run([]string{"gaps", "-module", dir, "-profile", filepath.Join(dir, "coverage.out")}, &stdout, &stderr)
+
+	// Assert
+	out := stdout.String()
+	want := "coverage gaps: 6 exported functions and 0 error branches without a test\n"
+	if !strings.HasSuffix(out, want) || strings.Contains(out, "\n\n") {
+		t.Errorf("report does not end in %q without a blank line:\n%s", want, out)
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/codemod"
+	"github.com/cristiano-pacheco/ai-rules/internal/gomod"
+	"github.com/cristiano-pacheco/ai-rules/internal/mockery"
+)
+
+func runGomock(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gomock", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	write := flags.Bool("w", false, "write the migrated files, update the config, and regenerate the mocks")
+	moduleDir := flags.String("module", ".", "root of the Go module")
+	configPath := flags.String("config", "", "path to the mockery config (default <module>/.mockery.yaml)")
+	binary := flags.String("mockery", "mockery", "mockery binary used to regenerate the mocks")
+	generate := flags.Bool("generate", true, "with -w, regenerate the mocks and remove the unused mockgen ones")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: ai-rules gomock [flags] [dir | dir/...]...")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Migrates gomock tests to mockery mocks and testify mock: removes the controllers, rewrites")
+		fmt.Fprintln(stderr, "EXPECT() chains and matchers, and moves the mocks to the mockery package. With -w, also")
+		fmt.Fprintln(stderr, "adds the mocked interfaces to .mockery.yaml, runs mockery, and deletes the mockgen mocks")
+		fmt.Fprintln(stderr, "nothing imports any more. Without -w, lists the changes and writes nothing. Directories")
+		fmt.Fprintln(stderr, "default to ./...")
+		fmt.Fprintln(stderr)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	module, err := gomod.NewModule(*moduleDir)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules gomock: %v\n", err)
+		return exitUsage
+	}
+	if *configPath == "" {
+		*configPath = filepath.Join(module.Dir, mockery.ConfigFileName)
+	}
+	runner := mockery.NewRunner(*binary, module.Dir, *configPath)
+	major := 0
+	if *write && *generate {
+		if major, err = runner.Check(); err != nil {
+			fmt.Fprintf(stderr, "ai-rules gomock: %v\n", err)
+			return exitUsage
+		}
+	}
+	cfg, err := loadMockeryConfig(*configPath, major)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules gomock: %v\n", err)
+		return exitUsage
+	}
+	dir := cfg.Dir()
+	if dir == "" || strings.Contains(dir, "{{") {
+		fmt.Fprintf(stderr, "ai-rules gomock: %s must set a fixed dir for the mocks\n", *configPath)
+		return exitUsage
+	}
+
+	migration := codemod.NewGomock(module.Path+"/"+path.Clean(filepath.ToSlash(dir)), cfg.WithExpecter())
+	result, err := codemod.NewRunner(migration).Run(patterns...)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules gomock: %v\n", err)
+		return exitUsage
+	}
+	if err := result.WriteText(stdout); err != nil {
+		fmt.Fprintf(stderr, "ai-rules gomock: %v\n", err)
+		return exitUsage
+	}
+	if !*write {
+		return exitOK
+	}
+
+	if err := result.Write(); err != nil {
+		fmt.Fprintf(stderr, "ai-rules gomock: %v\n", err)
+		return exitUsage
+	}
+	inv, err := mockery.NewScanner(module.Path, module.Dir).Scan()
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules gomock: %v\n", err)
+		return exitUsage
+	}
+	changes, err := addMockedInterfaces(cfg, inv, migration.Mocked())
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules gomock: %v\n", err)
+		return exitUsage
+	}
+	for _, change := range changes {
+		fmt.Fprintln(stdout, change)
+	}
+	if err := os.WriteFile(*configPath, cfg.Bytes(), 0o644); err != nil {
+		fmt.Fprintf(stderr, "ai-rules gomock: write config: %v\n", err)
+		return exitUsage
+	}
+	if !*generate {
+		return exitOK
+	}
+	// mockgen mocks left in the mockery directory would collide with the ones mockery writes there.
+	removed, err := runner.CleanMockGen(dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules gomock: %v\n", err)
+		return exitUsage
+	}
+	if len(removed) > 0 {
+		fmt.Fprintf(stdout, "removed %d mockgen mocks from %s\n", len(removed), dir)
+	}
+	if err := regenerateMocks(runner, cfg, stdout, stderr); err != nil {
+		fmt.Fprintf(stderr, "ai-rules gomock: %v\n", err)
+		return exitUsage
+	}
+	if err := removeMockGenMocks(runner, module, migration.MockPackages(), stdout); err != nil {
+		fmt.Fprintf(stderr, "ai-rules gomock: %v\n", err)
+		return exitUsage
+	}
+	return exitOK
+}
+
+// addMockedInterfaces lists the interfaces the migrated tests mock in the config. An interface name is looked
+// up in the module; one declared by no package, or by several, is reported for a human to add. It returns one
+// line per change or report.
+func addMockedInterfaces(cfg *mockery.Config, inv *mockery.Inventory, names []string) ([]string, error) {
+	var changes []string
+	for _, name := range names {
+		var found []mockery.Interface
+		for pkg, declared := range inv.Interfaces {
+			if declared[name] {
+				found = append(found, mockery.Interface{Package: pkg, Name: name})
+			}
+		}
+		switch {
+		case len(found) == 0:
+			changes = append(changes, fmt.Sprintf("skipped %s: no package of the module declares it", name))
+			continue
+		case len(found) > 1:
+			sort.Slice(found, func(i, j int) bool { return found[i].Package < found[j].Package })
+			changes = append(changes, fmt.Sprintf("skipped %s: declared in %s and %s", name, found[0].Package,
+				found[1].Package))
+			continue
+		}
+		if cfg.Has(found[0]) {
+			continue
+		}
+		if err := cfg.Add(found[0]); err != nil {
+			return nil, err
+		}
+		changes = append(changes, fmt.Sprintf("added %s", found[0]))
+	}
+	return changes, nil
+}
+
+// removeMockGenMocks deletes the mockgen files of the module's gomock mock packages no Go file imports any
+// more.
+func removeMockGenMocks(runner *mockery.Runner, module *gomod.Module, packages []string, stdout io.Writer) error {
+	for _, p := range packages {
+		rel, ok := strings.CutPrefix(p, module.Path+"/")
+		if !ok {
+			continue
+		}
+		imported, err := importedInModule(module.Dir, p)
+		if err != nil {
+			return err
+		}
+		if imported {
+			fmt.Fprintf(stdout, "kept the mockgen mocks in %s: still imported\n", rel)
+			continue
+		}
+		removed, err := runner.CleanMockGen(rel)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "removed %d mockgen mocks from %s\n", len(removed), rel)
+	}
+	return nil
+}
+
+// importedInModule reports whether a Go file of the module, outside vendor, testdata, and hidden directories,
+// imports importPath.
+func importedInModule(dir, importPath string) (bool, error) {
+	errFound := errors.New("found")
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p != dir && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") {
+			return nil
+		}
+		f, err := parser.ParseFile(fset, p, nil, parser.ImportsOnly)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", p, err)
+		}
+		for _, spec := range f.Imports {
+			if v, _ := strconv.Unquote(spec.Path.Value); v == importPath {
+				return errFound
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("scan imports: %w", err)
+	}
+	return false, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/mockery"
+)
+
+func TestAddMockedInterfaces_MockedNames_AddsThoseDeclaredOnce(t *testing.T) {
+	sender := mockery.Interface{Package: "example.com/m/mail", Name: "Sender"}
+	userRepository := mockery.Interface{Package: "example.com/m/repo", Name: "UserRepository"}
+	inv := &mockery.Inventory{
+		Module: "example.com/m",
+		Interfaces: map[string]map[string]bool{
+			"example.com/m/repo":  {"UserRepository": true, "Store": true},
+			"example.com/m/cache": {"Store": true},
+			"example.com/m/mail":  {"Sender": true},
+		},
+	}
+	tests := []struct {
+		name        string
+		names       []string
+		want        []string
+		wantMocking []mockery.Interface
+	}{
+		{
+			name:        "declared once",
+			names:       []string{"UserRepository"},
+			want:        []string{"added example.com/m/repo.UserRepository"},
+			wantMocking: []mockery.Interface{sender, userRepository},
+		},
+		{
+			name:        "declared nowhere",
+			names:       []string{"Clock"},
+			want:        []string{"skipped Clock: no package of the module declares it"},
+			wantMocking: []mockery.Interface{sender},
+		},
+		{
+			name:        "declared twice",
+			names:       []string{"Store"},
+			want:        []string{"skipped Store: declared in example.com/m/cache and example.com/m/repo"},
+			wantMocking: []mockery.Interface{sender},
+		},
+		{
+			name:        "already configured",
+			names:       []string{"Sender"},
+			want:        nil,
+			wantMocking: []mockery.Interface{sender},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cfg := mockery.NewConfig(2)
+			if err := cfg.Add(sender); err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			got, err := addMockedInterfaces(cfg, inv, tt.names)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("addMockedInterfaces: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changes = %q, want %q", got, tt.want)
+			}
+			if mocking := cfg.Interfaces(); !reflect.DeepEqual(mocking, tt.wantMocking) {
+				t.Errorf("Interfaces() = %v, want %v", mocking, tt.wantMocking)
+			}
+		})
+	}
+}
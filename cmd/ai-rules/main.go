@@ -0,0 +1,73 @@
+// Command ai-rules runs checks and code generators that enforce the skills in this repository on a Go module.
+//
+// Usage:
+//
+//	ai-rules <command> [flags]
+//
+// Run "ai-rules help" for the list of commands.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Exit codes shared by every command.
+const (
+	exitOK     = 0
+	exitFailed = 1 // the command ran and the policy failed
+	exitUsage  = 2 // bad flags, unreadable input, or an internal error
+)
+
+// command is one ai-rules subcommand.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func commands() []command {
+	return []command{
+		{name: "coverage", summary: "enforce coverage thresholds from ai-rules.yaml", run: runCoverage},
+		{name: "gaps", summary: "report untested exported functions and error branches with their rules", run: runGaps},
+		{name: "export", summary: "install the skills for the configured unit test flavor", run: runExport},
+		{name: "gomock", summary: "migrate gomock tests to mockery mocks and testify mock", run: runGomock},
+		{name: "mocks", summary: "sync .mockery.yaml with constructor dependencies and regenerate mocks", run: runMocks},
+		{name: "rewrite", summary: "run a codemod that rewrites tests toward the go-unit-tests conventions", run: runRewrite},
+		{name: "scaffold", summary: "generate test skeletons that follow go-unit-tests", run: runScaffold},
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stdout)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+	for _, cmd := range commands() {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdout, stderr)
+		}
+	}
+	fmt.Fprintf(stderr, "ai-rules: unknown command %q\n\n", args[0])
+	usage(stderr)
+	return exitUsage
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: ai-rules <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-12s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "ai-rules <command> -h" for command flags.`)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/gomod"
+	"github.com/cristiano-pacheco/ai-rules/internal/mockery"
+)
+
+func runMocks(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("mocks", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	moduleDir := flags.String("module", ".", "root of the Go module to scan")
+	configPath := flags.String("config", "", "path to the mockery config (default <module>/.mockery.yaml)")
+	binary := flags.String("mockery", "mockery", "mockery binary used to regenerate the mocks")
+	check := flags.Bool("check", false, "report what is out of sync and write nothing; exit 1 when anything is")
+	prune := flags.Bool("prune", false, "also remove entries for interfaces no constructor depends on")
+	generate := flags.Bool("generate", true, "regenerate the mocks after updating the config")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: ai-rules mocks [flags]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Adds the module interfaces that New* constructors take to .mockery.yaml, removes entries for")
+		fmt.Fprintln(stderr, "interfaces that no longer exist, then runs mockery and removes the mocks it no longer")
+		fmt.Fprintln(stderr, "generates, so test/mocks matches the actual dependencies. When mockery fails, the previous")
+		fmt.Fprintln(stderr, "mocks and .mockery.yaml are kept. mockery v2 and v3 are supported; a new .mockery.yaml")
+		fmt.Fprintln(stderr, "uses the keys of the installed version.")
+		fmt.Fprintln(stderr)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	module, err := gomod.NewModule(*moduleDir)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules mocks: %v\n", err)
+		return exitUsage
+	}
+	if *configPath == "" {
+		*configPath = filepath.Join(module.Dir, mockery.ConfigFileName)
+	}
+	inv, err := mockery.NewScanner(module.Path, module.Dir).Scan()
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules mocks: %v\n", err)
+		return exitUsage
+	}
+	runner := mockery.NewRunner(*binary, module.Dir, *configPath)
+	major := 0
+	if *generate && !*check {
+		if major, err = runner.Check(); err != nil {
+			fmt.Fprintf(stderr, "ai-rules mocks: %v\n", err)
+			return exitUsage
+		}
+	}
+	cfg, err := loadMockeryConfig(*configPath, major)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules mocks: %v\n", err)
+		return exitUsage
+	}
+
+	changes, err := syncMockeryConfig(cfg, inv, *prune)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules mocks: %v\n", err)
+		return exitUsage
+	}
+	for _, change := range changes {
+		fmt.Fprintln(stdout, change)
+	}
+	if *check {
+		if len(changes) > 0 {
+			return exitFailed
+		}
+		fmt.Fprintf(stdout, "%s is up to date\n", *configPath)
+		return exitOK
+	}
+
+	restore := func() error { return nil }
+	if len(changes) > 0 {
+		if restore, err = writeMockeryConfig(*configPath, cfg); err != nil {
+			fmt.Fprintf(stderr, "ai-rules mocks: write config: %v\n", err)
+			return exitUsage
+		}
+	}
+	if !*generate {
+		return exitOK
+	}
+	if err := regenerateMocks(runner, cfg, stdout, stderr); err != nil {
+		fmt.Fprintf(stderr, "ai-rules mocks: %v\n", err)
+		if err := restore(); err != nil {
+			fmt.Fprintf(stderr, "ai-rules mocks: restore config: %v\n", err)
+		}
+		return exitUsage
+	}
+	return exitOK
+}
+
+// writeMockeryConfig writes cfg to path, where mockery reads it, and returns a function that puts back what
+// was there before, removing the file when there was none.
+func writeMockeryConfig(path string, cfg *mockery.Config) (func() error, error) {
+	previous, err := os.ReadFile(path)
+	existed := err == nil
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err := os.WriteFile(path, cfg.Bytes(), 0o644); err != nil {
+		return nil, err
+	}
+	return func() error {
+		if !existed {
+			return os.Remove(path)
+		}
+		return os.WriteFile(path, previous, 0o644)
+	}, nil
+}
+
+// regenerateMocks runs mockery and, when the config names a fixed mocks directory, removes the mocks it no
+// longer generates.
+func regenerateMocks(runner *mockery.Runner, cfg *mockery.Config, stdout, stderr io.Writer) error {
+	dir := cfg.Dir()
+	if dir == "" || strings.Contains(dir, "{{") {
+		return runner.Run(stdout, stderr)
+	}
+	removed, err := runner.Regenerate(dir, stdout, stderr)
+	if err != nil {
+		return err
+	}
+	if len(removed) > 0 {
+		fmt.Fprintf(stdout, "removed %d stale mocks from %s\n", len(removed), dir)
+	}
+	return nil
+}
+
+// loadMockeryConfig reads the mockery config, or returns the default one for the major mockery version when
+// the file does not exist yet.
+func loadMockeryConfig(path string, major int) (*mockery.Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return mockery.NewConfig(major), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	cfg, err := mockery.ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// syncMockeryConfig adds the interfaces constructors depend on and removes entries for module interfaces
+// that are gone, or unused when prune is set. It returns one line per change.
+func syncMockeryConfig(cfg *mockery.Config, inv *mockery.Inventory, prune bool) ([]string, error) {
+	var changes []string
+	for _, dep := range inv.Dependencies {
+		if cfg.Has(dep.Interface) {
+			continue
+		}
+		if err := cfg.Add(dep.Interface); err != nil {
+			return nil, err
+		}
+		changes = append(changes, fmt.Sprintf("added %s (%s, %s)", dep.Interface, dep.Constructor, dep.Pos))
+	}
+	for _, i := range cfg.Interfaces() {
+		switch {
+		case !inv.Contains(i.Package):
+			continue
+		case !inv.Declares(i):
+			cfg.Remove(i)
+			changes = append(changes, fmt.Sprintf("removed %s (not declared)", i))
+		case prune && !inv.Uses(i):
+			cfg.Remove(i)
+			changes = append(changes, fmt.Sprintf("removed %s (no constructor depends on it)", i))
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/mockery"
+)
+
+func TestSyncMockeryConfig_StaleEntries_RemovesThem(t *testing.T) {
+	userRepository := mockery.Interface{Package: "example.com/m/repo", Name: "UserRepository"}
+	clock := mockery.Interface{Package: "example.com/m/repo", Name: "Clock"}
+	reader := mockery.Interface{Package: "github.com/other/lib", Name: "Reader"}
+	inv := &mockery.Inventory{
+		Module:     "example.com/m",
+		Interfaces: map[string]map[string]bool{"example.com/m/repo": {"UserRepository": true, "Clock": true}},
+		Dependencies: []mockery.Dependency{
+			{Interface: userRepository, Constructor: "user.NewUseCase", Pos: "user/user.go:9"},
+		},
+	}
+	tests := []struct {
+		name           string
+		prune          bool
+		want           []string
+		wantInterfaces []mockery.Interface
+	}{
+		{
+			name:  "undeclared entries",
+			prune: false,
+			want: []string{
+				"added example.com/m/repo.UserRepository (user.NewUseCase, user/user.go:9)",
+				"removed example.com/m/repo.Gone (not declared)",
+			},
+			wantInterfaces: []mockery.Interface{clock, userRepository, reader},
+		},
+		{
+			name:  "unused entries with prune",
+			prune: true,
+			want: []string{
+				"added example.com/m/repo.UserRepository (user.NewUseCase, user/user.go:9)",
+				"removed example.com/m/repo.Clock (no constructor depends on it)",
+				"removed example.com/m/repo.Gone (not declared)",
+			},
+			wantInterfaces: []mockery.Interface{userRepository, reader},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cfg, err := mockery.ParseConfig([]byte("dir: test/mocks\npackages:\n  example.com/m/repo:\n" +
+				"    interfaces:\n      Clock:\n      Gone:\n  github.com/other/lib:\n    interfaces:\n      Reader:\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			got, err := syncMockeryConfig(cfg, inv, tt.prune)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("syncMockeryConfig: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changes = %q, want %q", got, tt.want)
+			}
+			if interfaces := cfg.Interfaces(); !reflect.DeepEqual(interfaces, tt.wantInterfaces) {
+				t.Errorf("Interfaces() = %v, want %v", interfaces, tt.wantInterfaces)
+			}
+		})
+	}
+}
+
+func TestRunMocks_MockeryFails_KeepsConfig(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake mockery is a shell script")
+	}
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{name: "existing config", config: "dir: test/mocks\npackages:\n"},
+		{name: "no config"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			files := map[string]string{
+				"go.mod": "module example.com/m\n\ngo 1.24\n",
+				"user/user.go": "package user\n\ntype Clock interface{ Now() int }\n\n" +
+					"func NewUseCase(c Clock) int {\n\treturn 0\n}\n",
+			}
+			if tt.config != "" {
+				files[mockery.ConfigFileName] = tt.config
+			}
+			writeFiles(t, dir, files)
+			binary := filepath.Join(t.TempDir(), "mockery")
+			script := "#!/bin/sh\nif [ \"$1\" = version ]; then\n  echo v2.53.3\n  exit 0\nfi\nexit 1\n"
+			if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			var stdout, stderr bytes.Buffer
+
+			// Act
+			code := runMocks([]string{"-module", dir, "-mockery", binary}, &stdout, &stderr)
+
+			// Assert
+			if code != exitUsage {
+				t.Fatalf("exit code = %d, want %d; stderr:\n%s", code, exitUsage, stderr.String())
+			}
+			got, err := os.ReadFile(filepath.Join(dir, mockery.ConfigFileName))
+			switch {
+			case tt.config == "" && !errors.Is(err, fs.ErrNotExist):
+				t.Errorf("%s = %q, %v; want it not created", mockery.ConfigFileName, got, err)
+			case tt.config != "" && string(got) != tt.config:
+				t.Errorf("%s = %q, %v; want %q", mockery.ConfigFileName, got, err, tt.config)
+			}
+		})
+	}
+}
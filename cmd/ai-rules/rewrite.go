@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/codemod"
+)
+
+// codemodEntry is one rewrite selectable by name.
+type codemodEntry struct {
+	name    string
+	summary string
+	new     func() codemod.Codemod
+}
+
+func codemods() []codemodEntry {
+	return []codemodEntry{
+		{
+			name:    "test-names",
+			summary: "rename tests and suite methods to TestMethod_Scenario_Expectation",
+			new:     func() codemod.Codemod { return codemod.NewTestNames() },
+		},
+		{
+			name:    "require",
+			summary: "use require for error checks and guards, and drop s.Assert() chains",
+			new:     func() codemod.Codemod { return codemod.NewRequire() },
+		},
+		{
+			name:    "ginkgo",
+			summary: "convert Ginkgo specs to testify suites and test functions",
+			new:     func() codemod.Codemod { return codemod.NewGinkgo() },
+		},
+		{
+			name:    "suite",
+			summary: "group flat tests building the same sut into a testify suite with SetupTest",
+			new:     func() codemod.Codemod { return codemod.NewSuite() },
+		},
+	}
+}
+
+func runRewrite(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("rewrite", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	write := flags.Bool("w", false, "write the rewritten files instead of only listing the changes")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: ai-rules rewrite <codemod> [flags] [dir | dir/...]...")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Rewrites test files toward the go-unit-tests conventions. Without -w, lists the changes and")
+		fmt.Fprintln(stderr, "writes nothing. Directories default to ./...")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Codemods:")
+		for _, c := range codemods() {
+			fmt.Fprintf(stderr, "  %-16s %s\n", c.name, c.summary)
+		}
+		fmt.Fprintln(stderr)
+		flags.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "-help" {
+		flags.Usage()
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+	var entry *codemodEntry
+	for _, c := range codemods() {
+		if c.name == args[0] {
+			entry = &c
+		}
+	}
+	if entry == nil {
+		fmt.Fprintf(stderr, "ai-rules rewrite: unknown codemod %q\n", args[0])
+		return exitUsage
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	result, err := codemod.NewRunner(entry.new()).Run(patterns...)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules rewrite: %v\n", err)
+		return exitUsage
+	}
+	if err := result.WriteText(stdout); err != nil {
+		fmt.Fprintf(stderr, "ai-rules rewrite: %v\n", err)
+		return exitUsage
+	}
+	if *write {
+		if err := result.Write(); err != nil {
+			fmt.Fprintf(stderr, "ai-rules rewrite: %v\n", err)
+			return exitUsage
+		}
+	}
+	return exitOK
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cristiano-pacheco/ai-rules/generator"
+	"github.com/cristiano-pacheco/ai-rules/internal/gomod"
+	"github.com/cristiano-pacheco/ai-rules/internal/mockery"
+)
+
+func runScaffold(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("scaffold", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dir := flags.String("dir", "", "module-relative directory of the package under test (required)")
+	moduleDir := flags.String("module", ".", "root of the Go module")
+	only := flags.String("only", "", "comma-separated subjects to generate: Type, Type.Method, or Func (default all)")
+	mocksDir := flags.String("mocks", "test/mocks", "module-relative directory of the mockery mocks")
+	output := flags.String("o", "", "file to write; it must not exist yet (default stdout)")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: ai-rules scaffold -dir <package> [flags]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Generates test skeletons per go-unit-tests: a suite for types whose constructor takes")
+		fmt.Fprintln(stderr, "mockable dependencies, test functions for the rest. Every generated test fails with a TODO")
+		fmt.Fprintln(stderr, "until its assertions are written.")
+		fmt.Fprintln(stderr)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if *dir == "" {
+		fmt.Fprintln(stderr, "ai-rules scaffold: -dir is required")
+		return exitUsage
+	}
+
+	module, err := gomod.NewModule(*moduleDir)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules scaffold: %v\n", err)
+		return exitUsage
+	}
+	rel := path.Clean(filepath.ToSlash(*dir))
+	files, pkgName, err := generator.ParseDir(module.Abs(rel))
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules scaffold: %v\n", err)
+		return exitUsage
+	}
+	// Only interfaces can be mocked, and telling them apart from structs of other packages takes the module.
+	inv, err := mockery.NewScanner(module.Path, module.Dir).Scan()
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules scaffold: %v\n", err)
+		return exitUsage
+	}
+	subjects, skips := generator.Inspect(generator.Interfaces(inv.Interfaces), files...)
+	subjects, err = selectSubjects(subjects, skips, *only)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules scaffold: %v\n", err)
+		return exitUsage
+	}
+	if *only == "" {
+		for _, skip := range skips {
+			fmt.Fprintf(stderr, "ai-rules scaffold: skipped %s, a %s; write its tests by hand\n", skip.Name, skip.Reason)
+		}
+	}
+
+	importPath := module.Path
+	if rel != "." {
+		importPath += "/" + rel
+	}
+	src, err := generator.NewGenerator(generator.Options{
+		ImportPath:      importPath,
+		PackageName:     pkgName,
+		MocksImportPath: module.Path + "/" + path.Clean(filepath.ToSlash(*mocksDir)),
+	}).File(subjects)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-rules scaffold: %v\n", err)
+		return exitUsage
+	}
+
+	if *output == "" {
+		if _, err := stdout.Write(src); err != nil {
+			fmt.Fprintf(stderr, "ai-rules scaffold: %v\n", err)
+			return exitUsage
+		}
+		return exitOK
+	}
+	if err := writeNewFile(*output, src); err != nil {
+		fmt.Fprintf(stderr, "ai-rules scaffold: %v\n", err)
+		return exitUsage
+	}
+	fmt.Fprintf(stdout, "wrote %s\n", *output)
+	return exitOK
+}
+
+// selectSubjects keeps the subjects named in only: Type, its constructor, or Func for the whole subject,
+// Type.Method for one method. An empty only keeps every subject; a name that matches nothing is an error,
+// which says why when Inspect skipped it.
+func selectSubjects(subjects []generator.Subject, skips []generator.Skip, only string) ([]generator.Subject, error) {
+	if only == "" {
+		if len(subjects) == 0 {
+			return nil, errors.New("no exported functions or methods to test")
+		}
+		return subjects, nil
+	}
+	whole := map[string]bool{}
+	methods := map[string]map[string]bool{}
+	for _, name := range strings.Split(only, ",") {
+		typeName, method, ok := strings.Cut(strings.TrimSpace(name), ".")
+		if !ok {
+			whole[typeName] = true
+			continue
+		}
+		if methods[typeName] == nil {
+			methods[typeName] = map[string]bool{}
+		}
+		methods[typeName][method] = true
+	}
+
+	var selected []generator.Subject
+	for _, s := range subjects {
+		byCtor := s.Constructor != nil && whole[s.Constructor.Name]
+		if whole[s.Name] || byCtor {
+			delete(whole, s.Name)
+			delete(methods, s.Name)
+			if byCtor {
+				delete(whole, s.Constructor.Name)
+			}
+			selected = append(selected, s)
+			continue
+		}
+		wanted := methods[s.Name]
+		if wanted == nil {
+			continue
+		}
+		var kept []generator.Func
+		for _, m := range s.Methods {
+			if wanted[m.Name] {
+				kept = append(kept, m)
+				delete(wanted, m.Name)
+			}
+		}
+		if len(kept) > 0 {
+			s.Methods = kept
+			selected = append(selected, s)
+		}
+	}
+	var missing []string
+	for name := range whole {
+		missing = append(missing, name)
+	}
+	for typeName, wanted := range methods {
+		for method := range wanted {
+			missing = append(missing, typeName+"."+method)
+		}
+	}
+	for _, skip := range skips {
+		if whole[skip.Name] || methods[skip.Name] != nil {
+			return nil, fmt.Errorf("%s is a %s, which scaffold cannot generate tests for", skip.Name, skip.Reason)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("no subject %s in the package", strings.Join(missing, ", "))
+	}
+	return selected, nil
+}
+
+// writeNewFile writes data to a file that must not exist, so an existing test file is never overwritten.
+func writeNewFile(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("create output: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("write output: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// scaffoldPackage is a package with a type and its constructor, a function, and a generic function.
+const scaffoldPackage = `package p
+
+type Service struct{}
+
+func NewService() *Service {
+	return &Service{}
+}
+
+func (s *Service) Do() error {
+	return nil
+}
+
+func Sum(a, b int) int {
+	return a + b
+}
+
+func Map[T any](items []T) []T {
+	return items
+}
+`
+
+func TestRunScaffold_Only_SelectsSubject(t *testing.T) {
+	tests := []struct {
+		name     string
+		only     string
+		wantCode int
+		want     string
+	}{
+		{name: "function", only: "Sum", wantCode: exitOK, want: "func TestSum_ValidInput_Succeeds"},
+		{name: "method", only: "Service.Do", wantCode: exitOK, want: "func TestService_Do_ValidInput_Succeeds"},
+		{name: "constructor selects its type", only: "NewService", wantCode: exitOK, want: "TestService_Do"},
+		{name: "generic function", only: "Map", wantCode: exitUsage, want: "Map is a generic function"},
+		{name: "unknown name", only: "Missing", wantCode: exitUsage, want: "no subject Missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"go.mod": "module example.com/m\n\ngo 1.24\n", "p/p.go": scaffoldPackage})
+			var stdout, stderr bytes.Buffer
+
+			// Act
+			code := run([]string{"scaffold", "-module", dir, "-dir", "p", "-only", tt.only}, &stdout, &stderr)
+
+			// Assert
+			if code != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d; stderr: %s", code, tt.wantCode, stderr.String())
+			}
+			if out := stdout.String() + stderr.String(); !strings.Contains(out, tt.want) {
+				t.Errorf("output does not contain %q:\n%s", tt.want, out)
+			}
+		})
+	}
+}
+
+func TestRunScaffold_AllSubjects_ReportsSkipped(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"go.mod": "module example.com/m\n\ngo 1.24\n", "p/p.go": scaffoldPackage})
+	var stdout, stderr bytes.Buffer
+
+	// Act
+	code := run([]string{"scaffold", "-module", dir, "-dir", "p"}, &stdout, &stderr)
+
+	// Assert
+	if code != exitOK {
+		t.Fatalf("exit code = %d, want %d; stderr: %s", code, exitOK, stderr.String())
+	}
+	if want := "skipped Map, a generic function"; !strings.Contains(stderr.String(), want) {
+		t.Errorf("stderr does not contain %q:\n%s", want, stderr.String())
+	}
+	if strings.Contains(stdout.String(), "TestNewService") {
+		t.Errorf("constructor got tests of its own:\n%s", stdout.String())
+	}
+}
+
+func TestRunScaffold_OtherPackageDependencies_MocksOnlyInterfaces(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":           "module example.com/m\n\ngo 1.24\n",
+		"config/config.go": "package config\n\ntype Config struct{}\n\ntype Store interface {\n\tSave() error\n}\n",
+		"p/p.go": `package p
+
+import "example.com/m/config"
+
+type Service struct{}
+
+func NewService(cfg config.Config, store config.Store) *Service {
+	return &Service{}
+}
+
+func (s *Service) Do() error {
+	return nil
+}
+`,
+	})
+	var stdout, stderr bytes.Buffer
+
+	// Act
+	code := run([]string{"scaffold", "-module", dir, "-dir", "p"}, &stdout, &stderr)
+
+	// Assert
+	if code != exitOK {
+		t.Fatalf("exit code = %d, want %d; stderr: %s", code, exitOK, stderr.String())
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "mocks.NewMockStore(s.T())") || strings.Contains(out, "MockConfig") {
+		t.Errorf("want a mock for the Store interface only:\n%s", out)
+	}
+}
@@ -0,0 +1,186 @@
+package generator_test
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cristiano-pacheco/ai-rules/generator"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// packages are the testdata packages, each with a golden <name>_test.go.golden.
+var packages = []string{"service", "calc", "orders"}
+
+// support are the testdata packages that packages import, with no tests of their own.
+var support = []string{"config"}
+
+// interfaces are the interfaces of the support packages, as a mockery scan of the module finds them.
+var interfaces = generator.Interfaces{"example.com/app/config": {"Store": true}}
+
+func TestInspect_Declarations_ClassifiesSubjects(t *testing.T) {
+	// Arrange
+	files := parseTestdata(t, "calc")
+
+	// Act
+	subjects, skips := generator.Inspect(interfaces, files...)
+
+	// Assert
+	type summary struct {
+		kind        generator.Kind
+		name        string
+		constructor string
+	}
+	var got []summary
+	for _, s := range subjects {
+		sum := summary{kind: s.Kind, name: s.Name}
+		if s.Constructor != nil {
+			sum.constructor = s.Constructor.Name
+		}
+		got = append(got, sum)
+	}
+	want := []summary{
+		{kind: generator.KindType, name: "Calculator", constructor: "NewCalculator"},
+		{kind: generator.KindFunc, name: "Copy"},
+		{kind: generator.KindType, name: "Init"},
+		{kind: generator.KindFunc, name: "NewInit"},
+		{kind: generator.KindType, name: "Pool", constructor: "NewPool"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("subjects = %+v, want %+v", got, want)
+	}
+	wantSkips := []generator.Skip{{Name: "Box", Reason: "generic type"}, {Name: "Map", Reason: "generic function"}}
+	if !reflect.DeepEqual(skips, wantSkips) {
+		t.Errorf("skips = %+v, want %+v", skips, wantSkips)
+	}
+}
+
+func TestGenerator_File_Packages_MatchGolden(t *testing.T) {
+	for _, name := range packages {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			subjects, _ := generator.Inspect(interfaces, parseTestdata(t, name)...)
+			golden := filepath.Join("testdata", name, name+"_test.go.golden")
+
+			// Act
+			got, err := generator.NewGenerator(options(name)).File(subjects)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("File: %v", err)
+			}
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("generated tests differ from %s; run go test -update to see the diff\ngot:\n%s", golden, got)
+			}
+		})
+	}
+}
+
+// TestGenerator_File_Packages_Vet builds every golden file with go vet, against stand-ins for testify and
+// the mocks, so a skeleton that does not compile fails here rather than in a user's package.
+func TestGenerator_File_Packages_Vet(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go vet")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not on PATH")
+	}
+
+	// Arrange
+	testify, err := filepath.Abs(filepath.Join("testdata", "testify"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	goMod := "module example.com/app\n\ngo 1.24\n\nrequire github.com/stretchr/testify v1.10.0\n\n" +
+		"replace github.com/stretchr/testify => " + testify + "\n"
+	writeFile(t, filepath.Join(dir, "go.mod"), []byte(goMod))
+	for _, name := range append(packages, support...) {
+		copyDir(t, filepath.Join("testdata", name), filepath.Join(dir, name))
+		if _, err := os.Stat(filepath.Join("testdata", name, "mocks")); err == nil {
+			copyDir(t, filepath.Join("testdata", name, "mocks"), filepath.Join(dir, "test", "mocks"))
+		}
+	}
+	cmd := exec.Command(goBin, "vet", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+
+	// Act
+	out, err := cmd.CombinedOutput()
+
+	// Assert
+	if err != nil {
+		t.Errorf("go vet: %v\n%s", err, out)
+	}
+}
+
+func options(name string) generator.Options {
+	return generator.Options{ImportPath: "example.com/app/" + name, MocksImportPath: "example.com/app/test/mocks"}
+}
+
+func parseTestdata(t *testing.T, name string) []*ast.File {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join("testdata", name, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, path := range paths {
+		f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// copyDir copies the Go files of src into dst, renaming a golden file to the test file it stands for.
+func copyDir(t *testing.T, src, dst string) {
+	t.Helper()
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, ".golden") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(src, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, filepath.Join(dst, strings.TrimSuffix(name, ".golden")), data)
+	}
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,40 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ParseDir parses the files of the package in dir that Inspect expects, the non-test, non-generated Go
+// files, and returns them with the package name.
+func ParseDir(dir string) ([]*ast.File, string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, "", fmt.Errorf("read package: %w", err)
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		mode := parser.ParseComments | parser.SkipObjectResolution
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, mode)
+		if err != nil {
+			return nil, "", fmt.Errorf("parse package: %w", err)
+		}
+		if !ast.IsGenerated(f) {
+			files = append(files, f)
+		}
+	}
+	if len(files) == 0 {
+		return nil, "", fmt.Errorf("no Go source files in %s", dir)
+	}
+	return files, files[0].Name.Name, nil
+}
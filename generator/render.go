@@ -0,0 +1,593 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidOptions is returned when Options lack what the subjects need.
+var ErrInvalidOptions = errors.New("invalid generator options")
+
+// Import paths the skeletons depend on.
+const (
+	testifySuite   = "github.com/stretchr/testify/suite"
+	testifyAssert  = "github.com/stretchr/testify/assert"
+	testifyRequire = "github.com/stretchr/testify/require"
+)
+
+// Options locate the package under test and the generated mocks.
+type Options struct {
+	// ImportPath is the import path of the package under test.
+	ImportPath string
+	// PackageName is the name of the package under test. Empty means the last element of ImportPath.
+	PackageName string
+	// MocksImportPath is the package holding the mockery mocks, "<module>/test/mocks" in the project layout.
+	// Required when a subject is a suite.
+	MocksImportPath string
+}
+
+// Generator renders test skeletons for the subjects of one package.
+//
+// Every generated test compiles and fails with a "TODO" message until its assertions are written, so a
+// skeleton can never pass for a test nobody finished.
+type Generator struct {
+	opts Options
+}
+
+// NewGenerator returns a generator for opts, naming the package after its import path when PackageName is empty.
+func NewGenerator(opts Options) *Generator {
+	if opts.PackageName == "" {
+		opts.PackageName = importPath(opts.ImportPath).name()
+	}
+	return &Generator{opts: opts}
+}
+
+// File renders a complete external test file, package <name>_test, for subjects, formatted with gofmt.
+func (g *Generator) File(subjects []Subject) ([]byte, error) {
+	if g.opts.ImportPath == "" {
+		return nil, fmt.Errorf("%w: missing import path of the package under test", ErrInvalidOptions)
+	}
+	w := newFileWriter(g.opts)
+	for _, s := range subjects {
+		if s.Kind == KindSuite && g.opts.MocksImportPath == "" {
+			return nil, fmt.Errorf("%w: %s needs mocks, but no mocks import path is set", ErrInvalidOptions, s.Name)
+		}
+		if err := w.subject(s); err != nil {
+			return nil, err
+		}
+	}
+	src := w.bytes()
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, fmt.Errorf("format generated tests: %w", err)
+	}
+	return formatted, nil
+}
+
+// fileWriter accumulates the declarations of one test file and the imports they use.
+type fileWriter struct {
+	opts Options
+	body bytes.Buffer
+	// imports maps import paths to the names the generated code uses for them.
+	imports map[string]string
+}
+
+func newFileWriter(opts Options) *fileWriter {
+	w := &fileWriter{opts: opts, imports: map[string]string{}}
+	w.use("testing", "testing")
+	w.use(opts.ImportPath, opts.PackageName)
+	return w
+}
+
+func (w *fileWriter) subject(s Subject) error {
+	switch s.Kind {
+	case KindSuite:
+		w.suite(s)
+	case KindType:
+		for _, m := range s.Methods {
+			w.typeTests(s, m)
+		}
+	case KindFunc:
+		w.funcTests(*s.Func)
+	default:
+		return fmt.Errorf("generate tests for %s: unknown kind %v", s.Name, s.Kind)
+	}
+	return nil
+}
+
+// suite writes a Pattern 1 suite: mocks and sut as fields, built in SetupTest.
+func (w *fileWriter) suite(s Subject) {
+	suiteName := s.Name + "TestSuite"
+	suitePkg := w.use(testifySuite, "suite")
+	mocksPkg := w.use(w.opts.MocksImportPath, "mocks")
+	ctor := *s.Constructor
+
+	fmt.Fprintf(&w.body, "type %s struct {\n\t%s.Suite\n", suiteName, suitePkg)
+	fmt.Fprintf(&w.body, "\tsut %s\n", w.typeString(ctor.Results[0].Type, ctor))
+	var mockFields []string
+	for _, dep := range s.Deps {
+		if dep.Mock {
+			field := w.mockField(dep)
+			mockFields = append(mockFields, "s."+field)
+			fmt.Fprintf(&w.body, "\t%s *%s.%s\n", field, mocksPkg, w.mockType(dep.Type, ctor))
+		}
+	}
+	w.body.WriteString("}\n\n")
+
+	fmt.Fprintf(&w.body, "func (s *%s) SetupTest() {\n", suiteName)
+	taken := w.locals(ctor)
+	args := make([]string, 0, len(s.Deps))
+	for _, dep := range s.Deps {
+		switch {
+		case dep.Mock:
+			field := w.mockField(dep)
+			fmt.Fprintf(&w.body, "\ts.%s = %s.New%s(s.T())\n", field, mocksPkg, w.mockType(dep.Type, ctor))
+			args = append(args, "s."+field)
+		default:
+			name := dep.Name
+			for taken[name] {
+				name += "Arg"
+			}
+			taken[name] = true
+			arg, decl := w.value(name, dep.Type, ctor)
+			if decl != "" {
+				fmt.Fprintf(&w.body, "\t%s\n", decl)
+			}
+			args = append(args, arg)
+		}
+	}
+	call := fmt.Sprintf("%s.%s(%s)", w.opts.PackageName, ctor.Name, strings.Join(args, ", "))
+	if w.returnsError(ctor) {
+		fmt.Fprintf(&w.body, "\t%s := %s\n\ts.Require().NoError(err)\n\ts.sut = sut\n}\n\n", w.lhs("sut", ctor), call)
+	} else {
+		fmt.Fprintf(&w.body, "\ts.sut = %s\n}\n\n", call)
+	}
+
+	fmt.Fprintf(&w.body, "func Test%sSuite(t *testing.T) {\n\t%s.Run(t, new(%s))\n}\n\n", s.Name, suitePkg, suiteName)
+
+	for _, m := range s.Methods {
+		arrange := "// TODO: set the expectations this path needs"
+		if len(mockFields) > 0 {
+			arrange += " on " + strings.Join(mockFields, ", ")
+		}
+		w.test(testCase{
+			signature: fmt.Sprintf("func (s *%s) Test%s_ValidInput_Succeeds()", suiteName, m.Name),
+			arrange:   []string{arrange},
+			recv:      "s.sut",
+			fn:        m,
+			suite:     true,
+		})
+		if w.returnsError(m) {
+			w.test(testCase{
+				signature: fmt.Sprintf("func (s *%s) Test%s_DependencyFails_ReturnsError()", suiteName, m.Name),
+				arrange:   []string{"// TODO: make one dependency return an error"},
+				recv:      "s.sut",
+				fn:        m,
+				suite:     true,
+				wantErr:   true,
+			})
+		}
+	}
+}
+
+// typeTests writes Pattern 2 tests for one method of a type without mockable dependencies.
+func (w *fileWriter) typeTests(s Subject, m Func) {
+	var arrange, declared []string
+	if s.Constructor == nil {
+		arrange = append(arrange, fmt.Sprintf("var sut %s.%s", w.opts.PackageName, s.Name))
+	} else {
+		ctor := *s.Constructor
+		taken := w.locals(ctor)
+		args := make([]string, 0, len(s.Deps))
+		for _, dep := range s.Deps {
+			name := dep.Name
+			for taken[name] {
+				name += "Arg"
+			}
+			arg, decl := w.value(name, dep.Type, ctor)
+			if decl != "" {
+				taken[name] = true
+				declared = append(declared, name)
+				arrange = append(arrange, decl)
+			}
+			args = append(args, arg)
+		}
+		call := fmt.Sprintf("%s.%s(%s)", w.opts.PackageName, ctor.Name, strings.Join(args, ", "))
+		if w.returnsError(ctor) {
+			arrange = append(arrange, fmt.Sprintf("%s := %s", w.lhs("sut", ctor), call), "require.NoError(t, err)")
+			w.use(testifyRequire, "require")
+		} else {
+			arrange = append(arrange, "sut := "+call)
+		}
+	}
+	ctorErr := s.Constructor != nil && w.returnsError(*s.Constructor)
+	prefix := "Test" + s.Name + "_" + m.Name
+	w.test(testCase{
+		signature: fmt.Sprintf("func %s_ValidInput_Succeeds(t *testing.T)", prefix),
+		arrange:   arrange,
+		declared:  declared,
+		recv:      "sut",
+		fn:        m,
+		errInUse:  ctorErr,
+	})
+	if w.returnsError(m) {
+		w.test(testCase{
+			signature: fmt.Sprintf("func %s_InvalidInput_ReturnsError(t *testing.T)", prefix),
+			arrange:   arrange,
+			declared:  declared,
+			recv:      "sut",
+			fn:        m,
+			errInUse:  ctorErr,
+			wantErr:   true,
+		})
+	}
+}
+
+// funcTests writes Pattern 2 tests for an exported function.
+func (w *fileWriter) funcTests(fn Func) {
+	w.test(testCase{
+		signature: fmt.Sprintf("func Test%s_ValidInput_Succeeds(t *testing.T)", fn.Name),
+		recv:      w.opts.PackageName,
+		fn:        fn,
+	})
+	if w.returnsError(fn) {
+		w.test(testCase{
+			signature: fmt.Sprintf("func Test%s_InvalidInput_ReturnsError(t *testing.T)", fn.Name),
+			recv:      w.opts.PackageName,
+			fn:        fn,
+			wantErr:   true,
+		})
+	}
+}
+
+// testCase is one generated test function or suite method.
+type testCase struct {
+	signature string
+	// arrange holds statements that precede the argument declarations.
+	arrange []string
+	// declared are the variables arrange declares, which the arguments must not declare again.
+	declared []string
+	// recv is what the function is called on: the package name or a sut variable.
+	recv  string
+	fn    Func
+	suite bool
+	// errInUse is set when arrange already declared err.
+	errInUse bool
+	wantErr  bool
+}
+
+// test writes an Arrange-Act-Assert body that calls tc.fn with zero-valued arguments.
+func (w *fileWriter) test(tc testCase) {
+	fmt.Fprintf(&w.body, "%s {\n\t// Arrange\n", tc.signature)
+	for _, line := range tc.arrange {
+		fmt.Fprintf(&w.body, "\t%s\n", line)
+	}
+	args := w.arguments(tc.fn, tc.declared)
+	w.body.WriteString("\n\t// Act\n\t")
+
+	hasErr := w.returnsError(tc.fn)
+	values := len(tc.fn.Results)
+	if hasErr {
+		values--
+	}
+	var lhs, got []string
+	for i := range values {
+		name := "got"
+		switch {
+		case tc.wantErr:
+			name = "_"
+		case values > 1:
+			name = fmt.Sprintf("got%d", i+1)
+		}
+		lhs = append(lhs, name)
+		if name != "_" {
+			got = append(got, name)
+		}
+	}
+	if hasErr {
+		lhs = append(lhs, "err")
+	}
+	call := fmt.Sprintf("%s.%s(%s)", tc.recv, tc.fn.Name, strings.Join(args, ", "))
+	switch {
+	case len(lhs) == 0:
+		w.body.WriteString(call)
+	case len(got) == 0 && hasErr && tc.errInUse:
+		fmt.Fprintf(&w.body, "%s = %s", strings.Join(lhs, ", "), call)
+	default:
+		fmt.Fprintf(&w.body, "%s := %s", strings.Join(lhs, ", "), call)
+	}
+	w.body.WriteString("\n\n\t// Assert\n")
+
+	check := "require.%s(t, err)"
+	fail := "assert.Fail(t, %s)"
+	if tc.suite {
+		check = "s.Require().%s(err)"
+		fail = "s.Fail(%s)"
+	} else if hasErr {
+		w.use(testifyRequire, "require")
+	}
+	if !tc.suite {
+		w.use(testifyAssert, "assert")
+	}
+	var todo string
+	switch {
+	case tc.wantErr:
+		fmt.Fprintf(&w.body, "\t"+check+"\n", "Error")
+		todo = `"TODO: assert the sentinel with ErrorIs"`
+	case len(got) > 0:
+		if hasErr {
+			fmt.Fprintf(&w.body, "\t"+check+"\n", "NoError")
+		}
+		verbs := make([]string, len(got))
+		for i, name := range got {
+			verbs[i] = name + "=%v"
+		}
+		todo = fmt.Sprintf("%q, %q, %s", "TODO: assert on the result", strings.Join(verbs, " "), strings.Join(got, ", "))
+	default:
+		if hasErr {
+			fmt.Fprintf(&w.body, "\t"+check+"\n", "NoError")
+		}
+		todo = fmt.Sprintf("%q", "TODO: assert the effect of "+tc.fn.Name)
+	}
+	fmt.Fprintf(&w.body, "\t"+fail+"\n}\n\n", todo)
+}
+
+// arguments declares one zero-valued variable per parameter in the Arrange section and returns the call
+// arguments. Context parameters share one ctx; variadic parameters are left out of the call.
+func (w *fileWriter) arguments(fn Func, declared []string) []string {
+	taken := w.locals(fn)
+	for _, name := range declared {
+		taken[name] = true
+	}
+	var args []string
+	hasCtx := false
+	for i, p := range fn.Params {
+		if _, ok := p.Type.(*ast.Ellipsis); ok {
+			continue
+		}
+		if w.isContext(p.Type, fn) {
+			if !hasCtx {
+				fmt.Fprintf(&w.body, "\tctx := %s.Background()\n", w.use("context", "context"))
+				hasCtx = true
+			}
+			args = append(args, "ctx")
+			continue
+		}
+		name := p.Name
+		if name == "" || name == "_" {
+			name = fmt.Sprintf("arg%d", i+1)
+		}
+		for taken[name] {
+			name += "Arg"
+		}
+		taken[name] = true
+		fmt.Fprintf(&w.body, "\tvar %s %s\n", name, w.typeString(p.Type, fn))
+		args = append(args, name)
+	}
+	return args
+}
+
+// locals returns the names a test body that calls fn must not declare: the receivers and results the
+// generated code uses, and the packages the test file or fn's file imports.
+func (w *fileWriter) locals(fn Func) map[string]bool {
+	taken := map[string]bool{"s": true, "t": true, "sut": true, "got": true, "err": true, "ctx": true}
+	for _, name := range w.imports {
+		taken[name] = true
+	}
+	for name := range fn.imports {
+		taken[name] = true
+	}
+	return taken
+}
+
+// value returns the expression passed for a constructor parameter that is not mocked, and the statement
+// declaring it, if any. Contexts get context.Background(), loggers a discarding logger, everything else a
+// zero-valued variable.
+func (w *fileWriter) value(name string, expr ast.Expr, fn Func) (arg, decl string) {
+	if w.isContext(expr, fn) {
+		return w.use("context", "context") + ".Background()", ""
+	}
+	if star, ok := expr.(*ast.StarExpr); ok && w.isSelector(star.X, fn, "log/slog", "Logger") {
+		slog := w.use("log/slog", "slog")
+		return fmt.Sprintf("%s.New(%s.DiscardHandler)", slog, slog), ""
+	}
+	return name, fmt.Sprintf("var %s %s // TODO: a real or fake value", name, w.typeString(expr, fn))
+}
+
+// lhs returns the left-hand side for a call to fn, whose last result is an error: err alone for one result,
+// first and err for two, and blanks for the results in between for more.
+func (w *fileWriter) lhs(first string, fn Func) string {
+	switch n := len(fn.Results); n {
+	case 1:
+		return "err"
+	case 2:
+		return first + ", err"
+	default:
+		return first + ", " + strings.Repeat("_, ", n-2) + "err"
+	}
+}
+
+// mockField names the suite field of a mocked dependency after the constructor parameter: userRepo becomes
+// userRepoMock.
+func (w *fileWriter) mockField(dep Dependency) string {
+	if strings.HasSuffix(dep.Name, "Mock") {
+		return dep.Name
+	}
+	return dep.Name + "Mock"
+}
+
+// mockType returns the mockery type name for an interface type: ports.UserRepository becomes
+// MockUserRepository, ports.Repository[model.UserModel] becomes MockRepository[model.UserModel].
+func (w *fileWriter) mockType(expr ast.Expr, fn Func) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return "Mock" + t.Name
+	case *ast.SelectorExpr:
+		return "Mock" + t.Sel.Name
+	case *ast.IndexExpr:
+		return w.mockType(t.X, fn) + "[" + w.typeString(t.Index, fn) + "]"
+	case *ast.IndexListExpr:
+		args := make([]string, len(t.Indices))
+		for i, idx := range t.Indices {
+			args[i] = w.typeString(idx, fn)
+		}
+		return w.mockType(t.X, fn) + "[" + strings.Join(args, ", ") + "]"
+	default:
+		return "Mock" + types.ExprString(expr)
+	}
+}
+
+// typeString prints a type expression from fn's file as it must be written in the external test package:
+// types of the package under test are qualified, and every referenced package is imported.
+func (w *fileWriter) typeString(expr ast.Expr, fn Func) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if builtinTypes[t.Name] {
+			return t.Name
+		}
+		return w.opts.PackageName + "." + t.Name
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		if !ok {
+			return types.ExprString(t)
+		}
+		path, ok := fn.imports[pkg.Name]
+		if !ok {
+			return types.ExprString(t)
+		}
+		return w.use(path, pkg.Name) + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + w.typeString(t.X, fn)
+	case *ast.ParenExpr:
+		return "(" + w.typeString(t.X, fn) + ")"
+	case *ast.Ellipsis:
+		return "..." + w.typeString(t.Elt, fn)
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "[]" + w.typeString(t.Elt, fn)
+		}
+		return "[" + types.ExprString(t.Len) + "]" + w.typeString(t.Elt, fn)
+	case *ast.MapType:
+		return "map[" + w.typeString(t.Key, fn) + "]" + w.typeString(t.Value, fn)
+	case *ast.ChanType:
+		prefix := "chan "
+		switch t.Dir {
+		case ast.SEND:
+			prefix = "chan<- "
+		case ast.RECV:
+			prefix = "<-chan "
+		}
+		return prefix + w.typeString(t.Value, fn)
+	case *ast.FuncType:
+		s := "func(" + w.fieldTypes(t.Params, fn) + ")"
+		if t.Results == nil || len(t.Results.List) == 0 {
+			return s
+		}
+		results := w.fieldTypes(t.Results, fn)
+		if len(t.Results.List) == 1 && len(t.Results.List[0].Names) <= 1 {
+			return s + " " + results
+		}
+		return s + " (" + results + ")"
+	case *ast.IndexExpr:
+		return w.typeString(t.X, fn) + "[" + w.typeString(t.Index, fn) + "]"
+	case *ast.IndexListExpr:
+		args := make([]string, len(t.Indices))
+		for i, idx := range t.Indices {
+			args[i] = w.typeString(idx, fn)
+		}
+		return w.typeString(t.X, fn) + "[" + strings.Join(args, ", ") + "]"
+	default:
+		// Literal struct and interface types, which refer to no package-level names in practice.
+		return types.ExprString(expr)
+	}
+}
+
+func (w *fileWriter) fieldTypes(list *ast.FieldList, fn Func) string {
+	if list == nil {
+		return ""
+	}
+	var parts []string
+	for _, f := range list.List {
+		n := max(len(f.Names), 1)
+		for range n {
+			parts = append(parts, w.typeString(f.Type, fn))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (w *fileWriter) returnsError(fn Func) bool {
+	return len(fn.Results) > 0 && isError(fn.Results[len(fn.Results)-1].Type)
+}
+
+func (w *fileWriter) isContext(expr ast.Expr, fn Func) bool {
+	return w.isSelector(expr, fn, "context", "Context")
+}
+
+// isSelector reports whether expr names the type sel of the package at path, under whatever name fn's file
+// imports it.
+func (w *fileWriter) isSelector(expr ast.Expr, fn Func, path, sel string) bool {
+	s, ok := expr.(*ast.SelectorExpr)
+	if !ok || s.Sel.Name != sel {
+		return false
+	}
+	pkg, ok := s.X.(*ast.Ident)
+	return ok && fn.imports[pkg.Name] == path
+}
+
+// use records an import and returns the name the generated code refers to it by. A second package that
+// wants a name already taken gets a numbered alias.
+func (w *fileWriter) use(path, name string) string {
+	if existing, ok := w.imports[path]; ok {
+		return existing
+	}
+	taken := map[string]bool{}
+	for _, n := range w.imports {
+		taken[n] = true
+	}
+	alias := name
+	for i := 2; taken[alias]; i++ {
+		alias = fmt.Sprintf("%s%d", name, i)
+	}
+	w.imports[path] = alias
+	return alias
+}
+
+// bytes returns the file: package clause, imports (standard library first), and the declarations.
+func (w *fileWriter) bytes() []byte {
+	var std, other []string
+	for path, name := range w.imports {
+		spec := fmt.Sprintf("%q", path)
+		if name != importPath(path).name() {
+			spec = name + " " + spec
+		}
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			other = append(other, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "package %s_test\n\nimport (\n", w.opts.PackageName)
+	for _, spec := range std {
+		fmt.Fprintf(&out, "\t%s\n", spec)
+	}
+	if len(std) > 0 && len(other) > 0 {
+		out.WriteString("\n")
+	}
+	for _, spec := range other {
+		fmt.Fprintf(&out, "\t%s\n", spec)
+	}
+	out.WriteString(")\n\n")
+	out.Write(w.body.Bytes())
+	return out.Bytes()
+}
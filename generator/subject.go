@@ -0,0 +1,336 @@
+// Package generator emits test skeletons that follow the go-unit-tests decision tree: a testify suite for a
+// struct whose constructor takes mockable dependencies (Pattern 1), and top-level test functions for
+// functions, value objects, and structs without dependencies (Pattern 2).
+//
+// It works on syntax alone, so it needs neither a build nor type information and is fast enough to run from
+// an editor on every request. Inspect finds the subjects in parsed files, given the interfaces of the packages
+// they import; Generator renders them.
+//
+// The package is public so editor plugins and other tools can render skeletons without running ai-rules.
+package generator
+
+import (
+	"go/ast"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kind selects the test pattern for a subject.
+type Kind int
+
+const (
+	// KindSuite is a struct built by a constructor with at least one mockable dependency.
+	KindSuite Kind = iota + 1
+	// KindType is a named type with exported methods and no mockable dependency.
+	KindType
+	// KindFunc is an exported function that is not a constructor of another subject.
+	KindFunc
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindSuite:
+		return "suite"
+	case KindType:
+		return "type"
+	case KindFunc:
+		return "func"
+	default:
+		return "Kind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// Subject is one thing to generate tests for.
+type Subject struct {
+	Kind Kind
+	// Name is the type name for KindSuite and KindType, and the function name for KindFunc.
+	Name string
+	// Constructor is the New<Name> function of a type, or nil when the type has none.
+	Constructor *Func
+	// Func is the function under test, for KindFunc only.
+	Func *Func
+	// Methods are the exported methods of a type, sorted by name.
+	Methods []Func
+	// Deps are the constructor parameters, in order. Only KindSuite subjects have a mockable one.
+	Deps []Dependency
+}
+
+// Func is the signature of a function or method.
+type Func struct {
+	Name    string
+	Params  []Field
+	Results []Field
+	// imports maps the package names visible in the declaring file to their import paths.
+	imports map[string]string
+}
+
+// Field is one parameter or result. Name is empty for unnamed ones.
+type Field struct {
+	Name string
+	Type ast.Expr
+}
+
+// Dependency is a constructor parameter.
+type Dependency struct {
+	Name string
+	Type ast.Expr
+	// Mock is true for parameters a mockery mock can stand in for: named interface types, declared in the
+	// package or listed in the Interfaces passed to Inspect.
+	Mock bool
+}
+
+// Interfaces holds the exported interface names of the packages a package under test imports, keyed by
+// import path, such as the Interfaces of a mockery inventory. Syntax alone cannot tell an interface of
+// another package from a struct, and only an interface can be mocked.
+type Interfaces map[string]map[string]bool
+
+// Skip is an exported declaration Inspect generates no tests for, because syntax alone cannot tell which
+// type arguments a test should instantiate it with.
+type Skip struct {
+	// Name is the function or type name.
+	Name string
+	// Reason is "generic function" or "generic type".
+	Reason string
+}
+
+// Inspect returns the subjects declared in files, the non-test files of one package, and the exported
+// declarations it skipped. A constructor parameter of another package's type is mocked only when interfaces
+// lists the type; nil mocks none. Unexported declarations and methods on unexported types are left out. The
+// constructor of a subject is tested through it and is no subject of its own. Both results are sorted by
+// name.
+func Inspect(interfaces Interfaces, files ...*ast.File) ([]Subject, []Skip) {
+	in := &inspector{
+		interfaces: interfaces,
+		types:      map[string]*ast.TypeSpec{},
+		generic:    map[string]string{},
+		methods:    map[string][]Func{},
+		funcs:      map[string]Func{},
+	}
+	for _, f := range files {
+		in.collect(f)
+	}
+	return in.subjects(), in.skipped()
+}
+
+// inspector accumulates declarations across the files of a package.
+type inspector struct {
+	interfaces Interfaces
+	types      map[string]*ast.TypeSpec
+	// generic maps the exported generic declarations to the Skip reason.
+	generic map[string]string
+	methods map[string][]Func
+	funcs   map[string]Func
+}
+
+func (in *inspector) collect(f *ast.File) {
+	imports := in.imports(f)
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				switch {
+				case !ok || !ts.Name.IsExported():
+				case ts.TypeParams != nil:
+					in.generic[ts.Name.Name] = "generic type"
+				default:
+					in.types[ts.Name.Name] = ts
+				}
+			}
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			if d.Type.TypeParams != nil {
+				in.generic[d.Name.Name] = "generic function"
+				continue
+			}
+			fn := Func{
+				Name:    d.Name.Name,
+				Params:  in.fields(d.Type.Params),
+				Results: in.fields(d.Type.Results),
+				imports: imports,
+			}
+			if d.Recv == nil {
+				in.funcs[fn.Name] = fn
+				continue
+			}
+			if recv := in.receiverName(d.Recv.List[0].Type); ast.IsExported(recv) {
+				in.methods[recv] = append(in.methods[recv], fn)
+			}
+		}
+	}
+}
+
+func (in *inspector) subjects() []Subject {
+	var subjects []Subject
+	constructors := map[string]bool{}
+	for name, ts := range in.types {
+		ctor, hasCtor := in.funcs["New"+name]
+		hasCtor = hasCtor && len(ctor.Results) > 0 && !isError(ctor.Results[0].Type)
+		methods := in.methods[name]
+		if len(methods) == 0 {
+			continue
+		}
+		sort.Slice(methods, func(i, j int) bool { return methods[i].Name < methods[j].Name })
+		s := Subject{Kind: KindType, Name: name, Methods: methods}
+		if hasCtor {
+			constructors[ctor.Name] = true
+			s.Constructor = &ctor
+			s.Deps = in.dependencies(ctor, ts)
+		}
+		for _, dep := range s.Deps {
+			if dep.Mock {
+				s.Kind = KindSuite
+				break
+			}
+		}
+		subjects = append(subjects, s)
+	}
+	for name, fn := range in.funcs {
+		if constructors[name] {
+			continue
+		}
+		subjects = append(subjects, Subject{Kind: KindFunc, Name: name, Func: &fn})
+	}
+	sort.Slice(subjects, func(i, j int) bool { return subjects[i].Name < subjects[j].Name })
+	return subjects
+}
+
+// skipped returns the generic functions, and the generic types with exported methods.
+func (in *inspector) skipped() []Skip {
+	var skips []Skip
+	for name, reason := range in.generic {
+		if reason == "generic type" && len(in.methods[name]) == 0 {
+			continue
+		}
+		skips = append(skips, Skip{Name: name, Reason: reason})
+	}
+	sort.Slice(skips, func(i, j int) bool { return skips[i].Name < skips[j].Name })
+	return skips
+}
+
+// dependencies classifies the constructor parameters of the type declared by ts.
+func (in *inspector) dependencies(ctor Func, ts *ast.TypeSpec) []Dependency {
+	deps := make([]Dependency, 0, len(ctor.Params))
+	for i, p := range ctor.Params {
+		name := p.Name
+		if name == "" || name == "_" {
+			name = "dep" + strconv.Itoa(i+1)
+		}
+		deps = append(deps, Dependency{Name: name, Type: p.Type, Mock: in.mockable(p.Type, ts, ctor)})
+	}
+	return deps
+}
+
+// mockable reports whether a mockery mock can be passed for a parameter of type expr.
+func (in *inspector) mockable(expr ast.Expr, self *ast.TypeSpec, ctor Func) bool {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if builtinTypes[t.Name] {
+			return false
+		}
+		// A type of the same package: only interfaces are mocked.
+		decl, ok := in.types[t.Name]
+		if !ok || decl == self {
+			return false
+		}
+		_, isInterface := decl.Type.(*ast.InterfaceType)
+		return isInterface
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		return ok && in.interfaces[ctor.imports[pkg.Name]][t.Sel.Name]
+	case *ast.IndexExpr:
+		return in.mockable(t.X, self, ctor)
+	case *ast.IndexListExpr:
+		return in.mockable(t.X, self, ctor)
+	default:
+		// Pointers, slices, maps, funcs, channels, and literal struct or interface types.
+		return false
+	}
+}
+
+// imports maps the package names a file can refer to onto their import paths.
+func (in *inspector) imports(f *ast.File) map[string]string {
+	imports := make(map[string]string, len(f.Imports))
+	for _, spec := range f.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := importPath(path).name()
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name != "_" && name != "." {
+			imports[name] = path
+		}
+	}
+	return imports
+}
+
+func (in *inspector) fields(list *ast.FieldList) []Field {
+	if list == nil {
+		return nil
+	}
+	var fields []Field
+	for _, f := range list.List {
+		if len(f.Names) == 0 {
+			fields = append(fields, Field{Type: f.Type})
+			continue
+		}
+		for _, n := range f.Names {
+			fields = append(fields, Field{Name: n.Name, Type: f.Type})
+		}
+	}
+	return fields
+}
+
+// receiverName returns the base type name of a receiver expression: T, *T, T[P], or *T[P].
+func (in *inspector) receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return in.receiverName(t.X)
+	case *ast.IndexExpr:
+		return in.receiverName(t.X)
+	case *ast.IndexListExpr:
+		return in.receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	default:
+		return ""
+	}
+}
+
+// isError reports whether expr is the predeclared error type.
+func isError(expr ast.Expr) bool {
+	id, ok := expr.(*ast.Ident)
+	return ok && id.Name == "error"
+}
+
+// builtinTypes are the predeclared type names, which are never dependencies.
+var builtinTypes = map[string]bool{
+	"any": true, "bool": true, "byte": true, "comparable": true, "complex64": true, "complex128": true,
+	"error": true, "float32": true, "float64": true, "int": true, "int8": true, "int16": true, "int32": true,
+	"int64": true, "rune": true, "string": true, "uint": true, "uint8": true, "uint16": true, "uint32": true,
+	"uint64": true, "uintptr": true,
+}
+
+// importPath is a package import path.
+type importPath string
+
+// name guesses the package name the way goimports does: the last element, without a major version suffix,
+// a "go-" prefix, or a ".vN" suffix.
+func (p importPath) name() string {
+	elems := strings.Split(string(p), "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = elems[len(elems)-2]
+	}
+	if i := strings.Index(name, ".v"); i > 0 {
+		name = name[:i]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	return strings.ReplaceAll(name, "-", "")
+}
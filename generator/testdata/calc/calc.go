@@ -0,0 +1,60 @@
+// Package calc holds Pattern 2 subjects and the declarations the generator must not trip over.
+package calc
+
+import "context"
+
+type Calculator struct {
+	base int
+}
+
+// NewCalculator takes a parameter named like one of Add's.
+func NewCalculator(a int) (*Calculator, error) {
+	return &Calculator{base: a}, nil
+}
+
+func (c *Calculator) Add(a, b int) int {
+	return c.base + a + b
+}
+
+func (c *Calculator) Div(a, b int) (int, error) {
+	return a / b, nil
+}
+
+type Pool struct{}
+
+// NewPool returns more than a value and an error.
+func NewPool(ctx context.Context, size int) (*Pool, func(), error) {
+	return &Pool{}, func() {}, nil
+}
+
+func (p *Pool) Acquire(ctx context.Context) error {
+	return nil
+}
+
+type Dep struct{}
+
+type Init struct{}
+
+// NewInit returns only an error, so it builds no Init.
+func NewInit(d Dep) error {
+	return nil
+}
+
+func (Init) Run() {}
+
+// Copy takes two contexts and a variadic parameter.
+func Copy(ctx, parent context.Context, names ...string) error {
+	return nil
+}
+
+func Map[T, U any](items []T, f func(T) U) []U {
+	return nil
+}
+
+type Box[T any] struct {
+	v T
+}
+
+func (b Box[T]) Get() T {
+	return b.v
+}
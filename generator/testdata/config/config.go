@@ -0,0 +1,13 @@
+// Package config is imported by the orders package: a struct scaffold must not mock, and an interface it
+// must.
+package config
+
+import "context"
+
+type Config struct {
+	Prefix string
+}
+
+type Store interface {
+	Save(ctx context.Context, key string) error
+}
@@ -0,0 +1,17 @@
+// Package mocks stands in for the mockery output of the config package.
+package mocks
+
+import (
+	"context"
+	"testing"
+)
+
+type MockStore struct{}
+
+func NewMockStore(t *testing.T) *MockStore {
+	return &MockStore{}
+}
+
+func (m *MockStore) Save(ctx context.Context, key string) error {
+	return nil
+}
@@ -0,0 +1,22 @@
+// Package orders is a Pattern 1 subject whose dependencies come from another package: a config struct,
+// passed by value, and a store interface.
+package orders
+
+import (
+	"context"
+
+	"example.com/app/config"
+)
+
+type OrderService struct {
+	cfg   config.Config
+	store config.Store
+}
+
+func NewOrderService(cfg config.Config, store config.Store) *OrderService {
+	return &OrderService{cfg: cfg, store: store}
+}
+
+func (s *OrderService) Place(ctx context.Context, id string) error {
+	return s.store.Save(ctx, s.cfg.Prefix+id)
+}
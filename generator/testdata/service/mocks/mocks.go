@@ -0,0 +1,19 @@
+// Package mocks stands in for the mockery output of the service package.
+package mocks
+
+import (
+	"context"
+	"testing"
+
+	"example.com/app/service"
+)
+
+type MockUserRepository struct{}
+
+func NewMockUserRepository(t *testing.T) *MockUserRepository {
+	return &MockUserRepository{}
+}
+
+func (m *MockUserRepository) Find(ctx context.Context, id int) (*service.User, error) {
+	return nil, nil
+}
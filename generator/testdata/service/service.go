@@ -0,0 +1,33 @@
+// Package service is a Pattern 1 subject: its constructor takes a mockable repository.
+package service
+
+import (
+	"context"
+	"log/slog"
+)
+
+type User struct {
+	ID   int
+	Name string
+}
+
+type UserRepository interface {
+	Find(ctx context.Context, id int) (*User, error)
+}
+
+type UserService struct {
+	repo   UserRepository
+	logger *slog.Logger
+}
+
+func NewUserService(repo UserRepository, logger *slog.Logger, s string) (*UserService, error) {
+	return &UserService{repo: repo, logger: logger}, nil
+}
+
+func (svc *UserService) Get(ctx context.Context, id int) (*User, error) {
+	return svc.repo.Find(ctx, id)
+}
+
+func (svc *UserService) Rename(ctx context.Context, id int, name string) error {
+	return nil
+}
@@ -0,0 +1,12 @@
+// Package assert is the part of testify's assert API the generated tests call.
+package assert
+
+// TestingT is what assertions report to.
+type TestingT interface {
+	Errorf(format string, args ...any)
+}
+
+func Fail(t TestingT, failureMessage string, msgAndArgs ...any) bool {
+	t.Errorf("%s", failureMessage)
+	return false
+}
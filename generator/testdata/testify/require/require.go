@@ -0,0 +1,39 @@
+// Package require is the part of testify's require API the generated tests call.
+package require
+
+// TestingT is what assertions report to.
+type TestingT interface {
+	Errorf(format string, args ...any)
+	FailNow()
+}
+
+func NoError(t TestingT, err error, msgAndArgs ...any) {
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		t.FailNow()
+	}
+}
+
+func Error(t TestingT, err error, msgAndArgs ...any) {
+	if err == nil {
+		t.Errorf("expected an error")
+		t.FailNow()
+	}
+}
+
+// Assertions are the require functions bound to one TestingT.
+type Assertions struct {
+	t TestingT
+}
+
+func New(t TestingT) *Assertions {
+	return &Assertions{t: t}
+}
+
+func (a *Assertions) NoError(err error, msgAndArgs ...any) {
+	NoError(a.t, err, msgAndArgs...)
+}
+
+func (a *Assertions) Error(err error, msgAndArgs ...any) {
+	Error(a.t, err, msgAndArgs...)
+}
@@ -0,0 +1,40 @@
+// Package suite is the part of testify's suite API the generated tests call.
+package suite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestingSuite is what Run accepts.
+type TestingSuite interface {
+	T() *testing.T
+	SetT(t *testing.T)
+}
+
+// Suite is embedded by test suites.
+type Suite struct {
+	t *testing.T
+}
+
+func (s *Suite) T() *testing.T {
+	return s.t
+}
+
+func (s *Suite) SetT(t *testing.T) {
+	s.t = t
+}
+
+func (s *Suite) Require() *require.Assertions {
+	return require.New(s.t)
+}
+
+func (s *Suite) Fail(failureMessage string, msgAndArgs ...any) bool {
+	s.t.Errorf("%s", failureMessage)
+	return false
+}
+
+func Run(t *testing.T, s TestingSuite) {
+	s.SetT(t)
+}
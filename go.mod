@@ -0,0 +1,3 @@
+module github.com/cristiano-pacheco/ai-rules
+
+go 1.24
@@ -0,0 +1,283 @@
+// Package codemod rewrites Go test files toward the conventions of the go-unit-tests skill.
+//
+// Each Codemod edits the syntax trees of one package in place and reports its edits as Changes. Runner loads
+// the packages, applies a codemod, and prints or writes the files it changed, formatted with gofmt.
+package codemod
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Codemod rewrites the files of one package.
+type Codemod interface {
+	// Rewrite edits pkg in place and returns one Change per edit, or per place it had to leave alone.
+	Rewrite(pkg *Package) ([]Change, error)
+}
+
+// Package is the parsed Go files of one directory, test and non-test, in file name order. Generated files
+// are not loaded.
+type Package struct {
+	Dir   string
+	Fset  *token.FileSet
+	Files []*File
+}
+
+// File is one parsed file of a Package.
+type File struct {
+	// Path is the file name as passed to Runner, joined with the directory.
+	Path string
+	AST  *ast.File
+	// Src is the content the file was parsed from.
+	Src []byte
+	// Test is true for _test.go files.
+	Test bool
+	// Replacement, when a codemod sets it, is the new content of the file, used instead of printing AST.
+	Replacement []byte
+	// Removed, when a codemod sets it, deletes the file instead.
+	Removed bool
+}
+
+// closeGap joins the lines of a node just removed from the tree, so the printer does not keep them as blank
+// lines. Positions in the file move up, so it runs after the changes are recorded.
+func (f *File) closeGap(fset *token.FileSet, node ast.Node) {
+	file := fset.File(node.Pos())
+	if file == nil {
+		return
+	}
+	start, end := file.Line(node.Pos()), file.Line(node.End())
+	for line := start; line <= end && start < file.LineCount(); line++ {
+		file.MergeLine(start)
+	}
+}
+
+// Change is one edit a codemod made, or one spot it left for a human.
+type Change struct {
+	Pos token.Position
+	// Message describes the edit, such as "renamed TestCreate to TestExecute_ValidInput_Succeeds".
+	Message string
+	// Skipped is true when the codemod could not rewrite the spot safely and left it unchanged.
+	Skipped bool
+}
+
+func (c Change) String() string {
+	prefix := ""
+	if c.Skipped {
+		prefix = "skipped: "
+	}
+	return fmt.Sprintf("%s:%d: %s%s", c.Pos.Filename, c.Pos.Line, prefix, c.Message)
+}
+
+// Runner applies one codemod to a set of directories.
+type Runner struct {
+	codemod Codemod
+}
+
+// Result is what a run changed.
+type Result struct {
+	Changes []Change
+	// Files maps the name of every changed file to its rewritten, gofmt-formatted content.
+	Files map[string][]byte
+	// Removed lists the files a codemod emptied, to delete.
+	Removed []string
+}
+
+// NewRunner returns a runner that applies codemod.
+func NewRunner(codemod Codemod) *Runner {
+	return &Runner{codemod: codemod}
+}
+
+// Run loads every package below the patterns and applies the codemod. A pattern is a directory, or a
+// directory followed by "/..." for it and everything below; testdata, vendor, and hidden directories are
+// skipped. A package with a file that does not parse is reported as skipped and left alone. Nothing is
+// written: Result holds the new content of the changed files.
+func (r *Runner) Run(patterns ...string) (*Result, error) {
+	dirs, err := r.dirs(patterns)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Files: map[string][]byte{}}
+	for _, dir := range dirs {
+		pkg, err := r.load(dir)
+		var syntax scanner.ErrorList
+		if errors.As(err, &syntax) && len(syntax) > 0 {
+			// The codemods edit a package as a whole, so one file that does not parse leaves all of it alone.
+			msg := "package not rewritten, " + syntax[0].Msg
+			result.Changes = append(result.Changes, Change{Pos: syntax[0].Pos, Message: msg, Skipped: true})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(pkg.Files) == 0 {
+			continue
+		}
+		changes, err := r.codemod.Rewrite(pkg)
+		if err != nil {
+			return nil, fmt.Errorf("rewrite %s: %w", dir, err)
+		}
+		if err := r.collect(result, pkg, changes); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Write replaces the changed files on disk and deletes the removed ones.
+func (res *Result) Write() error {
+	names := make([]string, 0, len(res.Files))
+	for name := range res.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		info, err := os.Stat(name)
+		if err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+		if err := os.WriteFile(name, res.Files[name], info.Mode().Perm()); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+	for _, name := range res.Removed {
+		if err := os.Remove(name); err != nil {
+			return fmt.Errorf("remove %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// WriteText writes one line per change and a summary.
+func (res *Result) WriteText(w io.Writer) error {
+	skipped := 0
+	for _, c := range res.Changes {
+		if c.Skipped {
+			skipped++
+		}
+		fmt.Fprintln(w, c)
+	}
+	_, err := fmt.Fprintf(w, "%d changes in %d files, %d skipped\n", len(res.Changes)-skipped,
+		len(res.Files)+len(res.Removed), skipped)
+	if err != nil {
+		return fmt.Errorf("write result: %w", err)
+	}
+	return nil
+}
+
+// collect formats the files that changes touched and records them in result.
+func (r *Runner) collect(result *Result, pkg *Package, changes []Change) error {
+	edited := map[string]bool{}
+	for _, c := range changes {
+		if !c.Skipped {
+			edited[c.Pos.Filename] = true
+		}
+	}
+	for _, f := range pkg.Files {
+		if !edited[f.Path] {
+			continue
+		}
+		if f.Removed {
+			result.Removed = append(result.Removed, f.Path)
+			continue
+		}
+		if f.Replacement != nil {
+			src, err := format.Source(f.Replacement)
+			if err != nil {
+				return fmt.Errorf("format %s: %w", f.Path, err)
+			}
+			result.Files[f.Path] = src
+			continue
+		}
+		var buf bytes.Buffer
+		if err := format.Node(&buf, pkg.Fset, f.AST); err != nil {
+			return fmt.Errorf("format %s: %w", f.Path, err)
+		}
+		result.Files[f.Path] = buf.Bytes()
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i].Pos, changes[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Line < b.Line
+	})
+	result.Changes = append(result.Changes, changes...)
+	return nil
+}
+
+func (r *Runner) dirs(patterns []string) ([]string, error) {
+	var dirs []string
+	seen := map[string]bool{}
+	add := func(dir string) {
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, pattern := range patterns {
+		root, recursive := strings.CutSuffix(filepath.ToSlash(pattern), "/...")
+		if pattern == "..." {
+			root, recursive = ".", true
+		}
+		root = filepath.FromSlash(root)
+		if !recursive {
+			add(filepath.Clean(root))
+			continue
+		}
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return err
+			}
+			name := d.Name()
+			if p != root && (name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") ||
+				strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			add(p)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("walk %s: %w", pattern, err)
+		}
+	}
+	return dirs, nil
+}
+
+func (r *Runner) load(dir string) (*Package, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read package: %w", err)
+	}
+	pkg := &Package{Dir: dir, Fset: token.NewFileSet()}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read package: %w", err)
+		}
+		f, err := parser.ParseFile(pkg.Fset, path, src, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("parse package: %w", err)
+		}
+		if ast.IsGenerated(f) {
+			continue
+		}
+		pkg.Files = append(pkg.Files, &File{Path: path, AST: f, Src: src, Test: strings.HasSuffix(e.Name(), "_test.go")})
+	}
+	return pkg, nil
+}
@@ -0,0 +1,174 @@
+package codemod_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/codemod"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestResult_Write_ChangedAndRemovedFiles_UpdatesDisk(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	changed, removed := filepath.Join(dir, "a_test.go"), filepath.Join(dir, "a_suite_test.go")
+	for _, path := range []string{changed, removed} {
+		if err := os.WriteFile(path, []byte("package a_test\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result := &codemod.Result{
+		Files:   map[string][]byte{changed: []byte("package a_test\n\nvar _ = 1\n")},
+		Removed: []string{removed},
+	}
+
+	// Act
+	err := result.Write()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if got, err := os.ReadFile(changed); err != nil || string(got) != string(result.Files[changed]) {
+		t.Errorf("%s = %q, %v; want %q", changed, got, err, result.Files[changed])
+	}
+	if _, err := os.Stat(removed); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("%s still exists", removed)
+	}
+}
+
+func TestRunner_Run_FileDoesNotParse_SkipsItsPackage(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	files := map[string]string{
+		"good/good_test.go": `package good_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSum(t *testing.T) {
+	require.True(t, true)
+}
+`,
+		"bad/bad_test.go": "package bad_test\n\nfunc TestSum(t *testing.T) {\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Act
+	result, err := codemod.NewRunner(codemod.NewTestNames()).Run(dir + "/...")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var skipped []string
+	for _, c := range result.Changes {
+		if c.Skipped {
+			skipped = append(skipped, c.Pos.Filename)
+		}
+	}
+	bad, good := filepath.Join(dir, "bad", "bad_test.go"), filepath.Join(dir, "good", "good_test.go")
+	if !slices.Contains(skipped, bad) {
+		t.Errorf("skipped %q, want %s among them", skipped, bad)
+	}
+	if _, ok := result.Files[good]; !ok {
+		t.Errorf("changed %d files, want %s among them", len(result.Files), good)
+	}
+}
+
+// matchGolden runs c on each package below testdata/<dir>. A file c changes has a golden <file>.golden next
+// to it; one it deletes or leaves alone has none, and only a <file>_suite_test.go bootstrap may be deleted.
+// The spots c skips are listed in skipped.golden, which a package where c skips nothing does not have.
+func matchGolden(t *testing.T, c codemod.Codemod, dir string) {
+	t.Helper()
+	dirs, err := filepath.Glob(filepath.Join("testdata", filepath.FromSlash(dir), "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range dirs {
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			// Act
+			result, err := codemod.NewRunner(c).Run(dir)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			var skipped bytes.Buffer
+			for _, c := range result.Changes {
+				if c.Skipped {
+					c.Pos.Filename = filepath.Base(c.Pos.Filename)
+					fmt.Fprintln(&skipped, c)
+				}
+			}
+			golden := filepath.Join(dir, "skipped.golden")
+			if *update {
+				updateGolden(t, golden, skipped.Bytes(), skipped.Len() > 0)
+			}
+			if want, _ := os.ReadFile(golden); !bytes.Equal(skipped.Bytes(), want) {
+				t.Errorf("skipped:\n%s\nwant (%s):\n%s", skipped.Bytes(), golden, want)
+			}
+			paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, path := range paths {
+				got, changed := result.Files[path]
+				golden := path + ".golden"
+				if *update {
+					updateGolden(t, golden, got, changed)
+				}
+				want, err := os.ReadFile(golden)
+				switch {
+				case errors.Is(err, os.ErrNotExist) && !changed:
+				case err != nil:
+					t.Errorf("%s: changed without a golden file: %v", path, err)
+				case !changed && slices.Contains(result.Removed, path):
+					t.Errorf("%s: deleted, but has a golden file", path)
+				case !changed:
+					t.Errorf("%s: left alone, but has a golden file", path)
+				case !bytes.Equal(got, want):
+					t.Errorf("%s differs from %s; run go test -update to see the diff\ngot:\n%s", path, golden, got)
+				}
+			}
+			bootstraps, err := filepath.Glob(filepath.Join(dir, "*_suite_test.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(result.Removed, bootstraps) {
+				t.Errorf("removed %v, want the bootstrap files %v", result.Removed, bootstraps)
+			}
+		})
+	}
+}
+
+func updateGolden(t *testing.T, golden string, got []byte, changed bool) {
+	t.Helper()
+	if !changed {
+		if err := os.Remove(golden); err != nil && !errors.Is(err, os.ErrNotExist) {
+			t.Fatal(err)
+		}
+		return
+	}
+	if err := os.WriteFile(golden, got, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,1463 @@
+package codemod
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ginkgoContainers group specs; the focused F variants convert like the plain node.
+var ginkgoContainers = map[string]bool{
+	"Describe": true, "Context": true, "When": true, "FDescribe": true, "FContext": true, "FWhen": true,
+}
+
+var ginkgoSpecs = map[string]bool{"It": true, "Specify": true, "FIt": true, "FSpecify": true}
+
+var ginkgoTables = map[string]bool{"DescribeTable": true, "FDescribeTable": true}
+
+var ginkgoPending = map[string]bool{
+	"PDescribe": true, "PContext": true, "PWhen": true, "XDescribe": true, "XContext": true, "XWhen": true,
+	"PIt": true, "XIt": true, "PSpecify": true, "XSpecify": true, "PDescribeTable": true, "XDescribeTable": true,
+}
+
+// ginkgoNames are the identifiers the ginkgo and gomega dot imports provide. One left in converted code has no
+// testify equivalent.
+var ginkgoNames = map[string]bool{
+	"Describe": true, "Context": true, "When": true, "It": true, "Specify": true, "FDescribe": true,
+	"FContext": true, "FWhen": true, "FIt": true, "FSpecify": true, "PDescribe": true, "PContext": true,
+	"PWhen": true, "PIt": true, "PSpecify": true, "XDescribe": true, "XContext": true, "XWhen": true,
+	"XIt": true, "XSpecify": true, "DescribeTable": true, "FDescribeTable": true, "PDescribeTable": true,
+	"XDescribeTable": true, "Entry": true, "FEntry": true, "PEntry": true, "XEntry": true, "BeforeEach": true,
+	"AfterEach": true, "JustBeforeEach": true, "JustAfterEach": true, "BeforeAll": true, "AfterAll": true,
+	"BeforeSuite": true, "AfterSuite": true, "SynchronizedBeforeSuite": true, "SynchronizedAfterSuite": true,
+	"ReportBeforeEach": true, "ReportAfterEach": true, "ReportBeforeSuite": true, "ReportAfterSuite": true,
+	"By": true, "Fail": true, "Skip": true, "GinkgoT": true, "GinkgoWriter": true, "GinkgoHelper": true,
+	"GinkgoRecover": true, "GinkgoParallelProcess": true, "DeferCleanup": true, "SpecContext": true,
+	"RunSpecs": true, "RegisterFailHandler": true, "Ordered": true, "Serial": true, "Label": true,
+	"Focus": true, "Pending": true, "Offset": true, "FlakeAttempts": true, "MustPassRepeatedly": true,
+	"Expect": true, "Ω": true, "ExpectWithOffset": true, "Eventually": true, "EventuallyWithOffset": true,
+	"Consistently": true, "ConsistentlyWithOffset": true, "StopTrying": true, "NewWithT": true,
+	"RegisterTestingT": true, "InterceptGomegaFailures": true, "Default": true, "Succeed": true,
+	"HaveOccurred": true, "MatchError": true, "Equal": true, "BeEquivalentTo": true, "BeIdenticalTo": true,
+	"BeTrue": true, "BeFalse": true, "BeNil": true, "BeZero": true, "BeEmpty": true, "HaveLen": true,
+	"HaveCap": true, "ContainElement": true, "ContainElements": true, "ContainSubstring": true, "HaveKey": true,
+	"HaveKeyWithValue": true, "HavePrefix": true, "HaveSuffix": true, "ConsistOf": true,
+	"HaveExactElements": true, "HaveEach": true, "HaveField": true, "HaveValue": true, "BeNumerically": true,
+	"BeTemporally": true, "BeAssignableToTypeOf": true, "BeElementOf": true, "BeKeyOf": true, "MatchJSON": true,
+	"MatchXML": true, "MatchYAML": true, "MatchRegexp": true, "Panic": true, "PanicWith": true,
+	"BeClosed": true, "Receive": true, "BeSent": true, "Not": true, "And": true, "Or": true,
+	"SatisfyAll": true, "SatisfyAny": true, "Satisfy": true, "WithTransform": true, "BeARegularFile": true,
+	"BeADirectory": true, "BeAnExistingFile": true, "HaveHTTPStatus": true, "HaveHTTPBody": true,
+	"HaveHTTPHeaderWithValue": true,
+}
+
+// gomegaMatcher is the testify assertion for a Gomega matcher with a direct equivalent.
+type gomegaMatcher struct {
+	assert string
+	// negated is the assertion for the NotTo form, or "" when there is none.
+	negated string
+	args    int
+	// expectedFirst puts the matcher argument before the actual value, as in Equal(expected, actual).
+	expectedFirst bool
+	// required marks error checks, which stop the test.
+	required bool
+}
+
+var gomegaMatchers = map[string]gomegaMatcher{
+	"HaveOccurred":         {assert: "Error", negated: "NoError", required: true},
+	"Succeed":              {assert: "NoError", negated: "Error", required: true},
+	"MatchError":           {assert: "ErrorIs", negated: "NotErrorIs", args: 1, required: true},
+	"Equal":                {assert: "Equal", negated: "NotEqual", args: 1, expectedFirst: true},
+	"BeEquivalentTo":       {assert: "EqualValues", negated: "NotEqualValues", args: 1, expectedFirst: true},
+	"BeIdenticalTo":        {assert: "Same", negated: "NotSame", args: 1, expectedFirst: true},
+	"BeTrue":               {assert: "True", negated: "False"},
+	"BeFalse":              {assert: "False", negated: "True"},
+	"BeNil":                {assert: "Nil", negated: "NotNil"},
+	"BeZero":               {assert: "Zero", negated: "NotZero"},
+	"BeEmpty":              {assert: "Empty", negated: "NotEmpty"},
+	"HaveLen":              {assert: "Len", args: 1},
+	"ContainElement":       {assert: "Contains", negated: "NotContains", args: 1},
+	"ContainSubstring":     {assert: "Contains", negated: "NotContains", args: 1},
+	"HaveKey":              {assert: "Contains", negated: "NotContains", args: 1},
+	"BeAssignableToTypeOf": {assert: "IsType", args: 1, expectedFirst: true},
+	"MatchJSON":            {assert: "JSONEq", args: 1, expectedFirst: true},
+	"MatchYAML":            {assert: "YAMLEq", args: 1, expectedFirst: true},
+	"MatchRegexp":          {assert: "Regexp", negated: "NotRegexp", args: 1, expectedFirst: true},
+	"Panic":                {assert: "Panics", negated: "NotPanics"},
+}
+
+// numericAssertions maps the BeNumerically comparators to testify.
+var numericAssertions = map[string]string{
+	">": "Greater", ">=": "GreaterOrEqual", "<": "Less", "<=": "LessOrEqual", "==": "EqualValues",
+}
+
+// ginkgoFillers are dropped from the words of a node description.
+var ginkgoFillers = map[string]bool{"a": true, "an": true, "the": true}
+
+// ginkgoConditions open a Context description, or the condition part of an It description.
+var ginkgoConditions = map[string]bool{"when": true, "if": true, "given": true}
+
+// reserved are the names the converted code declares or refers to: the suite receiver, the *testing.T
+// parameter, and the table of a DescribeTable.
+var reserved = map[string]bool{"s": true, "t": true, "tests": true, "tt": true}
+
+// aaaComments are the section comments the converted tests get; existing ones are not repeated.
+var aaaComments = map[string]bool{"// Arrange": true, "// Act": true, "// Assert": true, "// Act & Assert": true}
+
+var (
+	exprType         = reflect.TypeOf((*ast.Expr)(nil)).Elem()
+	keyValueExprType = reflect.TypeOf(ast.KeyValueExpr{})
+)
+
+// Ginkgo converts Ginkgo specs to testify, following go-unit-tests. A top-level Describe with closure
+// variables or setup nodes becomes a suite: the variables become fields, BeforeEach and AfterEach become
+// SetupTest and TearDownTest, BeforeAll and AfterAll become SetupSuite and TearDownSuite, and each It becomes a
+// Test method. Any other Describe becomes test functions. Variables and BeforeEach bodies of nested containers
+// are inlined into each test, outer first, and their AfterEach bodies are deferred. A DescribeTable becomes a
+// table with a subtest per Entry.
+//
+// Tests are named TestMethod_Scenario_Expectation: Method is the call under test, found like test-names does,
+// or else the first nested Describe, or else the top-level one; Scenario joins the Context and When
+// descriptions, or the condition of an It such as "returns an error when the repository fails", or else falls
+// back like test-names; Expectation is the It description, or for a DescribeTable what its assertions check. Gomega
+// error checks become require or s.Require() assertions, and other matchers assert or suite assertions.
+//
+// A Describe using a node or matcher without a testify equivalent, such as Eventually, JustBeforeEach, or
+// GinkgoWriter, is reported and left alone, and so is every spec of a package with suite-level nodes like
+// BeforeSuite. The RunSpecs bootstrap is removed once no spec is left in the package, and its file with it
+// when nothing else is declared there.
+type Ginkgo struct {
+	names *TestNames
+}
+
+// ginkgoDescribe is one top-level Describe being converted.
+type ginkgoDescribe struct {
+	fset *token.FileSet
+	file *File
+	desc string
+	name string
+	// suite is true when the Describe becomes a testify suite rather than test functions.
+	suite    bool
+	typeName string
+	fields   []*ast.ValueSpec
+	// Setup and teardown bodies of the top-level container, by the suite method they become.
+	setupSuite, setupTest, tearDownTest, tearDownSuite []*ast.BlockStmt
+	// asserts are the assertion calls converted from Gomega ones; the first opens the Assert section.
+	asserts map[ast.Expr]bool
+	// taken holds the names declared in the package, and the ones given to converted tests so far.
+	taken map[string]bool
+	// claimed holds the names given to the tests of this Describe, taken once it converts.
+	claimed map[string]bool
+	tests   strings.Builder
+	changes []Change
+	err     error
+}
+
+// ginkgoScope is what a spec inherits from the containers around it, outermost first.
+type ginkgoScope struct {
+	method  string
+	path    []string
+	vars    []*ast.DeclStmt
+	befores []*ast.BlockStmt
+	afters  []*ast.BlockStmt
+}
+
+// NewGinkgo returns the ginkgo codemod.
+func NewGinkgo() *Ginkgo {
+	return &Ginkgo{names: NewTestNames()}
+}
+
+func (g *Ginkgo) Rewrite(pkg *Package) ([]Change, error) {
+	if pos, name := g.suiteNode(pkg); name != "" {
+		return []Change{{
+			Pos:     pkg.Fset.Position(pos),
+			Message: name + " has no testify equivalent; left the package's specs alone",
+			Skipped: true,
+		}}, nil
+	}
+	var changes []Change
+	taken := pkg.declared()
+	edits := map[*File][]sourceEdit{}
+	left := 0
+	for _, f := range pkg.Files {
+		if !f.Test {
+			continue
+		}
+		for _, decl := range f.AST.Decls {
+			call := g.topLevel(decl)
+			if call == nil {
+				continue
+			}
+			if !g.dotImported(f) {
+				left++
+				changes = append(changes, Change{
+					Pos: pkg.Fset.Position(decl.Pos()), Message: "Ginkgo is not dot-imported", Skipped: true,
+				})
+				continue
+			}
+			d := &ginkgoDescribe{
+				fset: pkg.Fset, file: f, asserts: map[ast.Expr]bool{}, taken: taken, claimed: map[string]bool{},
+			}
+			text, err := g.convert(d, call)
+			if err != nil {
+				left++
+				changes = append(changes, Change{
+					Pos:     pkg.Fset.Position(decl.Pos()),
+					Message: fmt.Sprintf("%s %q: %v", g.callee(call), d.desc, err),
+					Skipped: true,
+				})
+				continue
+			}
+			edits[f] = append(edits[f], sourceEdit{start: decl.Pos(), end: decl.End(), text: text})
+			changes = append(changes, d.changes...)
+		}
+	}
+	if len(edits) == 0 {
+		return changes, nil
+	}
+	if left == 0 {
+		changes = append(changes, g.removeBootstrap(pkg, edits)...)
+	}
+	for _, f := range pkg.Files {
+		if e, ok := edits[f]; ok && !f.Removed {
+			// The converted code uses testify, and leaves the Ginkgo and Gomega dot imports unused unless another
+			// Describe of the file was skipped.
+			src, err := f.edited(pkg.Fset, e, func(converted *File, fset *token.FileSet) {
+				for _, p := range []string{"testing", testifySuite, testifyRequire, testifyAssert} {
+					converted.addImport(fset, p)
+				}
+				if g.ginkgoName(converted.AST) == "" {
+					for _, p := range []string{ginkgoV2, ginkgoV1, gomega} {
+						converted.deleteImport(fset, p)
+					}
+				}
+			})
+			if err != nil {
+				return nil, err
+			}
+			f.Replacement = src
+		}
+	}
+	return changes, nil
+}
+
+// suiteNode returns a top-level node of pkg, other than a container, that wraps every spec of the package,
+// such as BeforeSuite or a global BeforeEach, or "".
+func (g *Ginkgo) suiteNode(pkg *Package) (token.Pos, string) {
+	for _, f := range pkg.Files {
+		if !f.Test {
+			continue
+		}
+		for _, decl := range f.AST.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				for _, value := range spec.(*ast.ValueSpec).Values {
+					call, ok := value.(*ast.CallExpr)
+					if !ok {
+						continue
+					}
+					name := g.callee(call)
+					if ginkgoNames[name] && !ginkgoContainers[name] && !ginkgoTables[name] && !ginkgoPending[name] {
+						return call.Pos(), name
+					}
+				}
+			}
+		}
+	}
+	return token.NoPos, ""
+}
+
+// topLevel returns the call of a var _ = Describe(...) declaration, or nil.
+func (g *Ginkgo) topLevel(decl ast.Decl) *ast.CallExpr {
+	gen, ok := decl.(*ast.GenDecl)
+	if !ok || gen.Tok != token.VAR || len(gen.Specs) != 1 {
+		return nil
+	}
+	spec := gen.Specs[0].(*ast.ValueSpec)
+	if len(spec.Names) != 1 || spec.Names[0].Name != "_" || len(spec.Values) != 1 {
+		return nil
+	}
+	call, ok := spec.Values[0].(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	if name := g.callee(call); ginkgoContainers[name] || ginkgoTables[name] || ginkgoPending[name] {
+		return call
+	}
+	return nil
+}
+
+func (g *Ginkgo) dotImported(f *File) bool {
+	for _, p := range []string{ginkgoV2, ginkgoV1} {
+		if name, ok := f.importName(p); ok && name == "." {
+			return true
+		}
+	}
+	return false
+}
+
+// callee returns the name of the function call calls when it is a plain identifier, or "".
+func (g *Ginkgo) callee(call *ast.CallExpr) string {
+	if id, ok := call.Fun.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
+
+// convert returns the testify code for the top-level container call, or the reason it cannot convert it.
+func (g *Ginkgo) convert(d *ginkgoDescribe, call *ast.CallExpr) (string, error) {
+	desc, lit, err := g.node(call)
+	d.desc = desc
+	if err != nil {
+		return "", err
+	}
+	if !ginkgoContainers[g.callee(call)] {
+		return "", fmt.Errorf("a top-level %s has no testify equivalent", g.callee(call))
+	}
+	if d.name = g.identifier(desc, false); d.name == "" {
+		return "", fmt.Errorf("cannot name a test after %q", desc)
+	}
+	hooks := map[string]*[]*ast.BlockStmt{
+		"BeforeAll": &d.setupSuite, "BeforeEach": &d.setupTest, "AfterEach": &d.tearDownTest,
+		"AfterAll": &d.tearDownSuite,
+	}
+	var nested []ast.Stmt
+	fields := map[string]bool{}
+	for _, stmt := range lit.Body.List {
+		if decl, ok := g.varDecl(stmt); ok {
+			for _, spec := range decl.Specs {
+				spec := spec.(*ast.ValueSpec)
+				if spec.Type == nil {
+					return "", fmt.Errorf("var %s has no type to declare a field with", spec.Names[0].Name)
+				}
+				d.fields = append(d.fields, spec)
+				for _, id := range spec.Names {
+					fields[id.Name] = true
+				}
+			}
+			continue
+		}
+		if hook, ok := g.exprCall(stmt); ok && hooks[g.callee(hook)] != nil {
+			body, err := g.hookBody(hook)
+			if err != nil {
+				return "", err
+			}
+			*hooks[g.callee(hook)] = append(*hooks[g.callee(hook)], body)
+			continue
+		}
+		nested = append(nested, stmt)
+	}
+	if name := d.file.redeclared(lit.Body.List, reserved); name != "" {
+		return "", fmt.Errorf("%s is declared inside the container, and the converted code uses it", name)
+	}
+	d.suite = len(d.fields) > 0 || len(d.setupSuite)+len(d.setupTest)+len(d.tearDownTest)+len(d.tearDownSuite) > 0
+	runner := ""
+	if d.suite {
+		d.typeName, runner = d.name+"TestSuite", "Test"+d.name+"Suite"
+		for _, name := range []string{d.typeName, runner} {
+			if d.taken[name] {
+				return "", fmt.Errorf("%s is already declared", name)
+			}
+		}
+		var others []ast.Stmt
+		for _, stmt := range lit.Body.List {
+			if _, ok := g.varDecl(stmt); !ok {
+				others = append(others, stmt)
+			}
+		}
+		if name := d.file.redeclared(others, fields); name != "" {
+			return "", fmt.Errorf("%s is redeclared inside the container", name)
+		}
+		g.rename(lit.Body, "s", fields)
+	}
+	g.replace(lit.Body, func(e ast.Expr) ast.Expr { return g.gomega(d, e) })
+	if d.err != nil {
+		return "", d.err
+	}
+	if err := g.walk(d, nested, ginkgoScope{}); err != nil {
+		return "", err
+	}
+	if d.tests.Len() == 0 {
+		return "", fmt.Errorf("no specs")
+	}
+
+	var b strings.Builder
+	if d.suite {
+		g.suiteType(d, &b, runner)
+		d.changes = append(d.changes, g.change(d, call.Pos(), "converted %s %q to %s", g.callee(call), desc,
+			d.typeName))
+	}
+	b.WriteString(d.tests.String())
+	if d.err != nil {
+		return "", d.err
+	}
+	for name := range d.claimed {
+		d.taken[name] = true
+	}
+	if d.suite {
+		d.taken[d.typeName], d.taken[runner] = true, true
+	}
+	return strings.TrimPrefix(b.String(), "\n"), nil
+}
+
+// suiteType writes the suite struct, its setup and teardown methods, and its runner.
+func (g *Ginkgo) suiteType(d *ginkgoDescribe, b *strings.Builder, runner string) {
+	fmt.Fprintf(b, "type %s struct {\n\tsuite.Suite\n", d.typeName)
+	var inits strings.Builder
+	for _, spec := range d.fields {
+		var names, targets []string
+		for _, id := range spec.Names {
+			names = append(names, id.Name)
+			targets = append(targets, "s."+id.Name)
+		}
+		fmt.Fprintf(b, "\t%s %s\n", strings.Join(names, ", "), g.print(d, spec.Type))
+		if len(spec.Values) > 0 {
+			var values []string
+			for _, v := range spec.Values {
+				values = append(values, g.print(d, v))
+			}
+			fmt.Fprintf(&inits, "%s = %s\n", strings.Join(targets, ", "), strings.Join(values, ", "))
+		}
+	}
+	b.WriteString("}\n")
+	g.hook(d, b, "SetupSuite", "", d.setupSuite)
+	g.hook(d, b, "SetupTest", inits.String(), d.setupTest)
+	g.hook(d, b, "TearDownTest", "", d.tearDownTest)
+	g.hook(d, b, "TearDownSuite", "", d.tearDownSuite)
+	fmt.Fprintf(b, "\nfunc %s(t *testing.T) {\n\tsuite.Run(t, new(%s))\n}\n", runner, d.typeName)
+}
+
+func (g *Ginkgo) hook(d *ginkgoDescribe, b *strings.Builder, method, prefix string, bodies []*ast.BlockStmt) {
+	if prefix == "" && len(bodies) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\nfunc (s *%s) %s() {\n%s", d.typeName, method, prefix)
+	for i, body := range bodies {
+		if i > 0 || prefix != "" {
+			b.WriteString("\n")
+		}
+		b.WriteString(g.stmts(d, body.List, body.Lbrace, body.Rbrace))
+	}
+	b.WriteString("}\n")
+}
+
+// walk converts the specs of a container body, given what the containers around it set up.
+func (g *Ginkgo) walk(d *ginkgoDescribe, list []ast.Stmt, sc ginkgoScope) error {
+	var nodes []*ast.CallExpr
+	for _, stmt := range list {
+		if decl, ok := g.varDecl(stmt); ok {
+			sc.vars = append(sc.vars[:len(sc.vars):len(sc.vars)], &ast.DeclStmt{Decl: decl})
+			continue
+		}
+		call, ok := g.exprCall(stmt)
+		if !ok {
+			return fmt.Errorf("line %d: statement outside a spec or setup node", d.fset.Position(stmt.Pos()).Line)
+		}
+		switch name := g.callee(call); {
+		case name == "BeforeEach" || name == "AfterEach":
+			body, err := g.hookBody(call)
+			if err != nil {
+				return err
+			}
+			if name == "BeforeEach" {
+				sc.befores = append(sc.befores[:len(sc.befores):len(sc.befores)], body)
+			} else {
+				sc.afters = append(sc.afters[:len(sc.afters):len(sc.afters)], body)
+			}
+		case ginkgoContainers[name] || ginkgoSpecs[name] || ginkgoTables[name]:
+			nodes = append(nodes, call)
+		case ginkgoNames[name]:
+			return fmt.Errorf("%s here has no testify equivalent", name)
+		default:
+			return fmt.Errorf("line %d: statement outside a spec or setup node", d.fset.Position(stmt.Pos()).Line)
+		}
+	}
+	for _, call := range nodes {
+		name := g.callee(call)
+		if ginkgoTables[name] {
+			if err := g.table(d, sc, call); err != nil {
+				return err
+			}
+			continue
+		}
+		desc, lit, err := g.node(call)
+		if err != nil {
+			return err
+		}
+		if ginkgoSpecs[name] {
+			if err := g.spec(d, sc, call, desc, lit); err != nil {
+				return err
+			}
+			continue
+		}
+		inner := sc
+		part := g.identifier(desc, !strings.HasSuffix(name, "Describe"))
+		if part == "" {
+			return fmt.Errorf("cannot name a test after %q", desc)
+		}
+		if sc.method == "" && len(sc.path) == 0 && strings.HasSuffix(name, "Describe") {
+			inner.method = part
+		} else {
+			inner.path = append(sc.path[:len(sc.path):len(sc.path)], part)
+		}
+		if err := g.walk(d, lit.Body.List, inner); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// spec converts an It to a test function or suite method.
+func (g *Ginkgo) spec(d *ginkgoDescribe, sc ginkgoScope, call *ast.CallExpr, desc string, lit *ast.FuncLit) error {
+	name := g.testName(d, sc, desc, lit.Body)
+	if name == "" {
+		return fmt.Errorf("cannot name a test after %q", desc)
+	}
+	body := g.body(d, sc, lit.Body, "")
+	if d.suite {
+		fmt.Fprintf(&d.tests, "\nfunc (s *%s) %s() {\n%s}\n", d.typeName, name, body)
+	} else {
+		fmt.Fprintf(&d.tests, "\nfunc %s(t *testing.T) {\n%s}\n", name, body)
+	}
+	d.changes = append(d.changes, g.change(d, call.Pos(), "converted %s %q to %s", g.callee(call), desc, name))
+	return nil
+}
+
+// table converts a DescribeTable to a test with a subtest per Entry.
+func (g *Ginkgo) table(d *ginkgoDescribe, sc ginkgoScope, call *ast.CallExpr) error {
+	desc, err := g.description(call)
+	if err != nil {
+		return err
+	}
+	at := -1
+	for i, arg := range call.Args {
+		if _, ok := arg.(*ast.FuncLit); ok {
+			at = i
+			break
+		}
+	}
+	if at < 0 {
+		return fmt.Errorf("DescribeTable %q has no table function", desc)
+	}
+	lit := call.Args[at].(*ast.FuncLit)
+	if lit.Type.Results != nil {
+		return fmt.Errorf("DescribeTable %q has a table function with results", desc)
+	}
+	params := map[string]bool{}
+	var fields []string
+	for _, field := range lit.Type.Params.List {
+		if _, ok := field.Type.(*ast.Ellipsis); ok {
+			return fmt.Errorf("DescribeTable %q has a variadic table function", desc)
+		}
+		var names []string
+		for _, id := range field.Names {
+			if reserved[id.Name] || id.Name == "name" || id.Name == "_" {
+				return fmt.Errorf("DescribeTable %q has a parameter named %s", desc, id.Name)
+			}
+			params[id.Name] = true
+			names = append(names, id.Name)
+		}
+		fields = append(fields, strings.Join(names, ", ")+" "+g.print(d, field.Type))
+	}
+	if name := d.file.redeclared(lit.Body.List, params); name != "" {
+		return fmt.Errorf("%s is redeclared inside the table function", name)
+	}
+	g.rename(lit.Body, "tt", params)
+
+	name := g.testName(d, sc, "", lit.Body)
+	if name == "" {
+		return fmt.Errorf("DescribeTable %q has no assertion to name the test after", desc)
+	}
+	var b strings.Builder
+	b.WriteString("tests := []struct {\nname string\n")
+	for _, field := range fields {
+		b.WriteString(field + "\n")
+	}
+	b.WriteString("}{\n")
+	for _, arg := range call.Args[at+1:] {
+		entry, ok := arg.(*ast.CallExpr)
+		if !ok || (g.callee(entry) != "Entry" && g.callee(entry) != "FEntry") {
+			return fmt.Errorf("DescribeTable %q has an argument other than Entry after the table function", desc)
+		}
+		if len(entry.Args) != len(params)+1 {
+			return fmt.Errorf("an Entry of %q passes %d values for %d parameters", desc, len(entry.Args)-1,
+				len(params))
+		}
+		row := []string{"name: " + g.print(d, entry.Args[0])}
+		i := 1
+		for _, field := range lit.Type.Params.List {
+			for _, id := range field.Names {
+				row = append(row, id.Name+": "+g.print(d, entry.Args[i]))
+				i++
+			}
+		}
+		fmt.Fprintf(&b, "{%s},\n", strings.Join(row, ", "))
+	}
+	b.WriteString("}\n\nfor _, tt := range tests {\n")
+	if d.suite {
+		prefix := ""
+		if len(d.setupTest) > 0 || len(d.fields) > 0 {
+			prefix = "s.SetupTest()\n\n"
+		}
+		fmt.Fprintf(&b, "s.Run(tt.name, func() {\n%s})\n}\n", g.body(d, sc, lit.Body, prefix))
+		fmt.Fprintf(&d.tests, "\nfunc (s *%s) %s() {\n%s}\n", d.typeName, name, b.String())
+	} else {
+		fmt.Fprintf(&b, "t.Run(tt.name, func(t *testing.T) {\n%s})\n}\n", g.body(d, sc, lit.Body, ""))
+		fmt.Fprintf(&d.tests, "\nfunc %s(t *testing.T) {\n%s}\n", name, b.String())
+	}
+	d.changes = append(d.changes, g.change(d, call.Pos(), "converted DescribeTable %q to %s", desc, name))
+	return nil
+}
+
+// body returns the statements of a test: the inherited variables, deferred AfterEach bodies, and BeforeEach
+// bodies as its Arrange section, followed by the spec body split into Act and Assert at its first assertion.
+// The Act is the statement before the assertion, or the call hoisted out of it when that statement calls
+// nothing under test.
+func (g *Ginkgo) body(d *ginkgoDescribe, sc ginkgoScope, block *ast.BlockStmt, prefix string) string {
+	var arrange []string
+	var vars strings.Builder
+	for _, decl := range sc.vars {
+		vars.WriteString(g.declare(d, decl, sc, block))
+	}
+	for _, after := range sc.afters {
+		arrange = append(arrange, "defer func() {\n"+g.stmts(d, after.List, after.Lbrace, after.Rbrace)+"}()\n")
+	}
+	for _, before := range sc.befores {
+		arrange = append(arrange, g.stmts(d, before.List, before.Lbrace, before.Rbrace))
+	}
+	list := block.List
+	first := -1
+	for i, stmt := range list {
+		if expr, ok := stmt.(*ast.ExprStmt); ok && d.asserts[expr.X] {
+			first = i
+			break
+		}
+	}
+	act := first - 1
+	if first < 0 {
+		act = len(list) - 1
+	}
+	var hoisted ast.Stmt
+	if act >= 0 && !g.acts(d, list[act]) {
+		// A statement calling nothing under test, such as a fixture literal, arranges; the call moves out of
+		// the first assertion instead, or the test has no Act.
+		act = -1
+		if first >= 0 {
+			hoisted = g.hoist(d, block, list[first].(*ast.ExprStmt).X.(*ast.CallExpr))
+		}
+	}
+	arranged := act
+	switch {
+	case act < 0 && first >= 0:
+		arranged = first
+	case act < 0:
+		arranged = len(list)
+	}
+	if arranged > 0 {
+		arrange = append(arrange, g.stmts(d, list[:arranged], block.Lbrace, token.NoPos))
+	}
+
+	if vars.Len() > 0 {
+		// The declarations lead the first part of the section, which assigns them.
+		if len(arrange) == 0 {
+			arrange = append(arrange, "")
+		}
+		arrange[0] = vars.String() + arrange[0]
+	}
+
+	var sections []string
+	if len(arrange) > 0 {
+		sections = append(sections, "// Arrange\n"+strings.Join(arrange, "\n"))
+	}
+	end := func(i int) token.Pos {
+		if i == len(list) {
+			return block.Rbrace
+		}
+		return token.NoPos
+	}
+	switch {
+	case act >= 0:
+		from := block.Lbrace
+		if act > 0 {
+			from = list[act-1].End()
+		}
+		sections = append(sections, "// Act\n"+g.stmts(d, list[act:act+1], from, end(act+1)))
+	case hoisted != nil:
+		sections = append(sections, "// Act\n"+g.print(d, hoisted)+"\n")
+	}
+	if first >= 0 {
+		from := block.Lbrace
+		if first > 0 {
+			from = list[first-1].End()
+		}
+		sections = append(sections, "// Assert\n"+g.stmts(d, list[first:], from, block.Rbrace))
+	}
+	return prefix + strings.Join(sections, "\n")
+}
+
+// acts reports whether stmt calls something other than a helper, the way the Act statement of a test does.
+func (g *Ginkgo) acts(d *ginkgoDescribe, stmt ast.Stmt) bool {
+	helpers := g.names.helpers(d.file, g.self(d))
+	helpers["assert"], helpers["require"] = true, true
+	found := false
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok && !g.names.helperCall(call, helpers) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// hoist moves the call under test out of an assertion into got := call, or err := call for an error check,
+// leaving the variable in its place, and returns that statement. It returns nil when the assertion has no
+// argument calling anything other than a helper, or when block already uses the name.
+func (g *Ginkgo) hoist(d *ginkgoDescribe, block *ast.BlockStmt, assertion *ast.CallExpr) ast.Stmt {
+	name := "got"
+	if errorChecks[g.names.callee(assertion.Fun)] {
+		name = "err"
+	}
+	taken := false
+	ast.Inspect(block, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == name {
+			taken = true
+		}
+		return !taken
+	})
+	if taken {
+		return nil
+	}
+	for i := len(assertion.Args) - 1; i >= 0; i-- {
+		call, ok := assertion.Args[i].(*ast.CallExpr)
+		if !ok || !g.acts(d, &ast.ExprStmt{X: call}) {
+			continue
+		}
+		assertion.Args[i] = &ast.Ident{NamePos: call.Pos(), Name: name}
+		lhs := &ast.Ident{NamePos: call.Pos(), Name: name}
+		return &ast.AssignStmt{Lhs: []ast.Expr{lhs}, TokPos: call.Pos(), Tok: token.DEFINE, Rhs: []ast.Expr{call}}
+	}
+	return nil
+}
+
+// declare prints an inherited var declaration, as a short declaration when it has values and no type. A
+// variable the test never reads is assigned to the blank identifier, or the test would not compile.
+func (g *Ginkgo) declare(d *ginkgoDescribe, decl *ast.DeclStmt, sc ginkgoScope, block *ast.BlockStmt) string {
+	gen := decl.Decl.(*ast.GenDecl)
+	var b strings.Builder
+	var unread []string
+	for _, spec := range gen.Specs {
+		spec := spec.(*ast.ValueSpec)
+		var names []string
+		for _, id := range spec.Names {
+			names = append(names, id.Name)
+			if !g.read(id.Name, sc, block) {
+				unread = append(unread, id.Name)
+			}
+		}
+		if spec.Type == nil && len(spec.Values) > 0 {
+			var values []string
+			for _, v := range spec.Values {
+				values = append(values, g.print(d, v))
+			}
+			fmt.Fprintf(&b, "%s := %s\n", strings.Join(names, ", "), strings.Join(values, ", "))
+			continue
+		}
+		fmt.Fprintf(&b, "%s\n", g.print(d, &ast.DeclStmt{Decl: &ast.GenDecl{Tok: token.VAR, Specs: []ast.Spec{spec}}}))
+	}
+	for _, name := range unread {
+		fmt.Fprintf(&b, "_ = %s\n", name)
+	}
+	return b.String()
+}
+
+// read reports whether a test built from sc and block reads the variable name, other than by assigning it.
+func (g *Ginkgo) read(name string, sc ginkgoScope, block *ast.BlockStmt) bool {
+	nodes := []ast.Node{block}
+	for _, decl := range sc.vars {
+		nodes = append(nodes, decl)
+	}
+	for _, body := range sc.befores {
+		nodes = append(nodes, body)
+	}
+	for _, body := range sc.afters {
+		nodes = append(nodes, body)
+	}
+	for _, n := range nodes {
+		if g.reads(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Ginkgo) reads(node ast.Node, name string) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ValueSpec:
+			for _, v := range n.Values {
+				found = found || g.reads(v, name)
+			}
+			return false
+		case *ast.AssignStmt:
+			if n.Tok != token.ASSIGN {
+				break
+			}
+			for _, lhs := range n.Lhs {
+				if _, ok := lhs.(*ast.Ident); !ok {
+					found = found || g.reads(lhs, name)
+				}
+			}
+			for _, rhs := range n.Rhs {
+				found = found || g.reads(rhs, name)
+			}
+			return false
+		case *ast.SelectorExpr:
+			found = found || g.reads(n.X, name)
+			return false
+		case *ast.Ident:
+			found = found || n.Name == name
+		}
+		return !found
+	})
+	return found
+}
+
+// testName returns TestMethod_Scenario_Expectation for a spec, or for a table when desc is "", made unique in
+// the package, or "".
+func (g *Ginkgo) testName(d *ginkgoDescribe, sc ginkgoScope, desc string, body *ast.BlockStmt) string {
+	expectation, scenario := g.identifier(desc, false), strings.Join(sc.path, "")
+	if desc == "" {
+		expectation = g.expectation(d, body)
+	}
+	if scenario == "" {
+		words := strings.Fields(desc)
+		for i := 1; i < len(words)-1; i++ {
+			if ginkgoConditions[strings.ToLower(words[i])] {
+				expectation = g.identifier(strings.Join(words[:i], " "), false)
+				scenario = g.identifier(strings.Join(words[i+1:], " "), false)
+				break
+			}
+		}
+	}
+	if expectation == "" {
+		return ""
+	}
+	method := g.method(d, sc, body)
+	if rest, ok := strings.CutPrefix(scenario, method); ok && rest != "" && unicode.IsUpper([]rune(rest)[0]) {
+		// "when add overflows" for a spec acting on Add: the scenario repeats the method.
+		scenario = rest
+	}
+	if scenario == "" {
+		scenario = g.names.scenario(body, g.expectation(d, body))
+	}
+	name := "Test" + method + "_" + scenario + "_" + expectation
+	unique := name
+	for i := 2; d.taken[unique] || d.claimed[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	d.claimed[unique] = true
+	return unique
+}
+
+// expectation names what the assertions of a converted spec body check, as test-names does.
+func (g *Ginkgo) expectation(d *ginkgoDescribe, body *ast.BlockStmt) string {
+	self := ""
+	if d.suite {
+		self = g.self(d)
+	}
+	return g.names.expectation(body, self, map[string]bool{"assert": true, "require": true})
+}
+
+// self returns the name the converted specs refer to their test by.
+func (g *Ginkgo) self(d *ginkgoDescribe) string {
+	if d.suite {
+		return "s"
+	}
+	return "t"
+}
+
+// method returns the name of the function or method a spec tests, as test-names finds it: the first call in
+// its Act statement or first assertion, other than a helper, or else the first call on sut or s.sut. Without
+// either, it is the first nested Describe, or the top-level one.
+func (g *Ginkgo) method(d *ginkgoDescribe, sc ginkgoScope, body *ast.BlockStmt) string {
+	list := body.List
+	from, to := len(list)-1, len(list)
+	for i, stmt := range list {
+		if expr, ok := stmt.(*ast.ExprStmt); ok && d.asserts[expr.X] {
+			from, to = max(i-1, 0), i+1
+			break
+		}
+	}
+	name, helpers := "", g.names.helpers(d.file, g.self(d))
+	helpers["assert"], helpers["require"] = true, true
+	for _, stmt := range list[max(from, 0):to] {
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok && name == "" && !g.names.helperCall(call, helpers) {
+				name = g.names.callee(call.Fun)
+			}
+			return name == ""
+		})
+	}
+	if name == "" {
+		ast.Inspect(body, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok && name == "" && g.names.onSUT(call.Fun) {
+				name = g.names.callee(call.Fun)
+			}
+			return name == ""
+		})
+	}
+	switch {
+	case name != "":
+		return g.names.camel(name)
+	case sc.method != "":
+		return sc.method
+	}
+	return d.name
+}
+
+// identifier turns a node description into an exported identifier part: "creates the user" gives
+// CreatesUser. With condition set, a leading "when", "if", or "given" is dropped too. It returns ""
+// when the description has no letter to start with.
+func (g *Ginkgo) identifier(desc string, condition bool) string {
+	words := strings.FieldsFunc(desc, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	var b strings.Builder
+	for i, w := range words {
+		lower := strings.ToLower(w)
+		if len(words) > 1 && (ginkgoFillers[lower] || (i == 0 && condition && ginkgoConditions[lower])) {
+			continue
+		}
+		b.WriteString(g.names.camel(w))
+	}
+	name := b.String()
+	if r, _ := utf8.DecodeRuneInString(name); !unicode.IsLetter(r) {
+		return ""
+	}
+	return name
+}
+
+// node returns the description of a container or spec call and its body.
+func (g *Ginkgo) node(call *ast.CallExpr) (string, *ast.FuncLit, error) {
+	desc, err := g.description(call)
+	if err != nil {
+		return desc, nil, err
+	}
+	lit, ok := call.Args[len(call.Args)-1].(*ast.FuncLit)
+	if !ok {
+		return desc, nil, fmt.Errorf("%s %q has no function body", g.callee(call), desc)
+	}
+	if len(lit.Type.Params.List) > 0 {
+		return desc, nil, fmt.Errorf("%s %q takes a SpecContext", g.callee(call), desc)
+	}
+	return desc, lit, nil
+}
+
+func (g *Ginkgo) description(call *ast.CallExpr) (string, error) {
+	if len(call.Args) == 0 {
+		return "", fmt.Errorf("%s without arguments", g.callee(call))
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", fmt.Errorf("%s needs a string literal description", g.callee(call))
+	}
+	desc, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", fmt.Errorf("%s needs a string literal description", g.callee(call))
+	}
+	return desc, nil
+}
+
+// hookBody returns the body of a setup or teardown node. A body that returns early cannot be inlined.
+func (g *Ginkgo) hookBody(call *ast.CallExpr) (*ast.BlockStmt, error) {
+	name := g.callee(call)
+	if len(call.Args) != 1 {
+		return nil, fmt.Errorf("%s with %d arguments", name, len(call.Args))
+	}
+	lit, ok := call.Args[0].(*ast.FuncLit)
+	if !ok || len(lit.Type.Params.List) > 0 {
+		return nil, fmt.Errorf("%s needs a function literal without parameters", name)
+	}
+	returns := false
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.ReturnStmt:
+			returns = true
+		case *ast.FuncLit:
+			return false
+		}
+		return !returns
+	})
+	if returns {
+		return nil, fmt.Errorf("%s returns early", name)
+	}
+	return lit.Body, nil
+}
+
+func (g *Ginkgo) varDecl(stmt ast.Stmt) (*ast.GenDecl, bool) {
+	decl, ok := stmt.(*ast.DeclStmt)
+	if !ok {
+		return nil, false
+	}
+	gen, ok := decl.Decl.(*ast.GenDecl)
+	return gen, ok && gen.Tok == token.VAR
+}
+
+func (g *Ginkgo) exprCall(stmt ast.Stmt) (*ast.CallExpr, bool) {
+	expr, ok := stmt.(*ast.ExprStmt)
+	if !ok {
+		return nil, false
+	}
+	call, ok := expr.X.(*ast.CallExpr)
+	return call, ok
+}
+
+// rename turns each use of names in n into a field of recv.
+func (g *Ginkgo) rename(n ast.Node, recv string, names map[string]bool) {
+	g.replace(n, func(e ast.Expr) ast.Expr {
+		id, ok := e.(*ast.Ident)
+		if !ok || !names[id.Name] {
+			return nil
+		}
+		return g.selector(id.Pos(), recv, id.Name)
+	})
+}
+
+// replace swaps each expression of n for which fn returns a replacement, children first. The identifier keys
+// of key-value pairs are field names and stay.
+func (g *Ginkgo) replace(n ast.Node, fn func(ast.Expr) ast.Expr) {
+	g.replaceValue(reflect.ValueOf(n), fn)
+}
+
+func (g *Ginkgo) replaceValue(v reflect.Value, fn func(ast.Expr) ast.Expr) {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if !v.IsNil() {
+			g.replaceValue(v.Elem(), fn)
+		}
+	case reflect.Slice:
+		for i := range v.Len() {
+			g.replaceField(v.Index(i), fn)
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type() == keyValueExprType && v.Type().Field(i).Name == "Key" {
+				if _, ok := v.Field(i).Interface().(*ast.Ident); ok {
+					continue
+				}
+			}
+			g.replaceField(v.Field(i), fn)
+		}
+	}
+}
+
+func (g *Ginkgo) replaceField(f reflect.Value, fn func(ast.Expr) ast.Expr) {
+	g.replaceValue(f, fn)
+	if f.Type() != exprType || f.IsNil() {
+		return
+	}
+	if r := fn(f.Interface().(ast.Expr)); r != nil {
+		f.Set(reflect.ValueOf(r))
+	}
+}
+
+// gomega returns the testify form of a Gomega assertion or Ginkgo helper call, or nil for any other
+// expression.
+func (g *Ginkgo) gomega(d *ginkgoDescribe, e ast.Expr) ast.Expr {
+	call, ok := e.(*ast.CallExpr)
+	if !ok || d.err != nil {
+		return nil
+	}
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		switch fun.Name {
+		case "GinkgoT":
+			if !d.suite {
+				return &ast.Ident{NamePos: call.Pos(), Name: "t"}
+			}
+			return &ast.CallExpr{Fun: g.selector(call.Pos(), "s", "T"), Lparen: call.Lparen, Rparen: call.Rparen}
+		case "By":
+			if len(call.Args) != 1 {
+				g.fail(d, fmt.Errorf("By with a function has no testify equivalent"))
+				return nil
+			}
+			return g.testingCall(d, call, "Log")
+		case "Skip":
+			return g.testingCall(d, call, "Skip")
+		case "GinkgoHelper":
+			return g.testingCall(d, call, "Helper")
+		case "DeferCleanup":
+			if len(call.Args) != 1 {
+				g.fail(d, fmt.Errorf("DeferCleanup with arguments has no testify equivalent"))
+				return nil
+			}
+			return g.testingCall(d, call, "Cleanup")
+		case "Fail":
+			return g.assert(d, call, "FailNow", true, call.Args)
+		}
+	case *ast.SelectorExpr:
+		expect, ok := fun.X.(*ast.CallExpr)
+		if !ok || (g.callee(expect) != "Expect" && g.callee(expect) != "Ω") {
+			return nil
+		}
+		switch fun.Sel.Name {
+		case "To", "Should":
+			return g.assertion(d, call, expect, false)
+		case "NotTo", "ToNot", "ShouldNot":
+			return g.assertion(d, call, expect, true)
+		}
+	}
+	return nil
+}
+
+// assertion converts Expect(actual).To(matcher, msg...) and its negated forms.
+func (g *Ginkgo) assertion(d *ginkgoDescribe, call, expect *ast.CallExpr, negated bool) ast.Expr {
+	if len(expect.Args) != 1 {
+		g.fail(d, fmt.Errorf("Expect of %d values has no testify equivalent", len(expect.Args)))
+		return nil
+	}
+	if len(call.Args) == 0 {
+		g.fail(d, fmt.Errorf("Expect without a matcher"))
+		return nil
+	}
+	actual, matcher := expect.Args[0], call.Args[0]
+	for {
+		m, ok := matcher.(*ast.CallExpr)
+		if !ok || g.callee(m) != "Not" || len(m.Args) != 1 {
+			break
+		}
+		negated, matcher = !negated, m.Args[0]
+	}
+	m, ok := matcher.(*ast.CallExpr)
+	if !ok || g.callee(m) == "" {
+		g.fail(d, fmt.Errorf("matcher %s has no testify equivalent", types.ExprString(matcher)))
+		return nil
+	}
+	name, args, required, err := g.matcher(m, actual, negated)
+	if err != nil {
+		g.fail(d, err)
+		return nil
+	}
+	r := g.assert(d, call, name, required, append(args, call.Args[1:]...))
+	d.asserts[r] = true
+	return r
+}
+
+// matcher returns the testify assertion for a Gomega matcher applied to actual, its arguments, and whether it
+// is an error check.
+func (g *Ginkgo) matcher(m *ast.CallExpr, actual ast.Expr, negated bool) (string, []ast.Expr, bool, error) {
+	name := g.callee(m)
+	for _, arg := range m.Args {
+		if call, ok := arg.(*ast.CallExpr); ok && ginkgoNames[g.callee(call)] {
+			return "", nil, false, fmt.Errorf("%s of a matcher has no testify equivalent", name)
+		}
+	}
+	unsupported := fmt.Errorf("%s has no testify equivalent", name)
+	if negated {
+		unsupported = fmt.Errorf("negated %s has no testify equivalent", name)
+	}
+	switch name {
+	case "MatchError":
+		lit, ok := m.Args[0].(*ast.BasicLit)
+		if len(m.Args) != 1 || !ok || lit.Kind != token.STRING {
+			break
+		}
+		if negated {
+			return "", nil, false, unsupported
+		}
+		return "EqualError", []ast.Expr{actual, lit}, true, nil
+	case "HaveField":
+		lit, ok := m.Args[0].(*ast.BasicLit)
+		if len(m.Args) != 2 || !ok || lit.Kind != token.STRING {
+			return "", nil, false, fmt.Errorf("HaveField needs a literal field path and a value")
+		}
+		path, _ := strconv.Unquote(lit.Value)
+		field := actual
+		for _, part := range strings.Split(path, ".") {
+			method, isCall := strings.CutSuffix(part, "()")
+			field = &ast.SelectorExpr{X: field, Sel: &ast.Ident{NamePos: actual.Pos(), Name: method}}
+			if isCall {
+				field = &ast.CallExpr{Fun: field, Lparen: actual.Pos(), Rparen: actual.Pos()}
+			}
+		}
+		if negated {
+			return "NotEqual", []ast.Expr{m.Args[1], field}, false, nil
+		}
+		return "Equal", []ast.Expr{m.Args[1], field}, false, nil
+	case "BeNil":
+		if len(m.Args) != 0 || !g.isError(actual) {
+			break
+		}
+		if negated {
+			return "Error", []ast.Expr{actual}, true, nil
+		}
+		return "NoError", []ast.Expr{actual}, true, nil
+	case "ConsistOf":
+		if len(m.Args) == 0 {
+			break
+		}
+		assert := "ElementsMatch"
+		if negated {
+			assert = "NotElementsMatch"
+		}
+		// A single argument other than a literal is taken for the expected slice; elements are passed in one.
+		expected := m.Args[0]
+		if _, ok := expected.(*ast.BasicLit); ok || len(m.Args) > 1 {
+			pos := m.Args[0].Pos()
+			expected = &ast.CompositeLit{
+				Type:   &ast.ArrayType{Lbrack: pos, Elt: &ast.Ident{NamePos: pos, Name: "any"}},
+				Lbrace: pos, Elts: m.Args, Rbrace: m.Rparen,
+			}
+		}
+		return assert, []ast.Expr{expected, actual}, false, nil
+	case "BeNumerically":
+		lit, ok := m.Args[0].(*ast.BasicLit)
+		if len(m.Args) < 2 || !ok || lit.Kind != token.STRING || negated {
+			return "", nil, false, unsupported
+		}
+		op, _ := strconv.Unquote(lit.Value)
+		if op == "~" && len(m.Args) == 3 {
+			return "InDelta", []ast.Expr{m.Args[1], actual, m.Args[2]}, false, nil
+		}
+		if assert, ok := numericAssertions[op]; ok && len(m.Args) == 2 {
+			if op == "==" {
+				return assert, []ast.Expr{m.Args[1], actual}, false, nil
+			}
+			return assert, []ast.Expr{actual, m.Args[1]}, false, nil
+		}
+		return "", nil, false, fmt.Errorf("BeNumerically(%s) has no testify equivalent", lit.Value)
+	}
+	spec, ok := gomegaMatchers[name]
+	if !ok || (negated && spec.negated == "") {
+		return "", nil, false, unsupported
+	}
+	if len(m.Args) != spec.args {
+		return "", nil, false, fmt.Errorf("%s with %d arguments has no testify equivalent", name, len(m.Args))
+	}
+	assert := spec.assert
+	if negated {
+		assert = spec.negated
+	}
+	args := []ast.Expr{actual}
+	switch {
+	case spec.args == 0:
+	case spec.expectedFirst:
+		args = []ast.Expr{m.Args[0], actual}
+	default:
+		args = append(args, m.Args[0])
+	}
+	return assert, args, spec.required, nil
+}
+
+// isError reports whether actual is named like an error: err, repoErr, or a field such as s.err.
+func (g *Ginkgo) isError(actual ast.Expr) bool {
+	var name string
+	switch e := actual.(type) {
+	case *ast.Ident:
+		name = e.Name
+	case *ast.SelectorExpr:
+		name = e.Sel.Name
+	}
+	return name == "err" || strings.HasSuffix(name, "Err")
+}
+
+// assert builds the call to a testify assertion: s.Require().X or s.X in a suite, require.X or assert.X
+// with t otherwise.
+func (g *Ginkgo) assert(d *ginkgoDescribe, call *ast.CallExpr, name string, required bool, args []ast.Expr) ast.Expr {
+	pos := call.Pos()
+	var fun ast.Expr
+	switch {
+	case d.suite && required:
+		require := &ast.CallExpr{Fun: g.selector(pos, "s", "Require"), Lparen: pos, Rparen: pos}
+		fun = &ast.SelectorExpr{X: require, Sel: &ast.Ident{NamePos: pos, Name: name}}
+	case d.suite:
+		fun = g.selector(pos, "s", name)
+	case required:
+		fun = g.selector(pos, "require", name)
+		args = append([]ast.Expr{&ast.Ident{NamePos: pos, Name: "t"}}, args...)
+	default:
+		fun = g.selector(pos, "assert", name)
+		args = append([]ast.Expr{&ast.Ident{NamePos: pos, Name: "t"}}, args...)
+	}
+	return &ast.CallExpr{Fun: fun, Lparen: call.Lparen, Args: args, Rparen: call.Rparen}
+}
+
+// testingCall calls a method of the test's *testing.T with the arguments of call.
+func (g *Ginkgo) testingCall(d *ginkgoDescribe, call *ast.CallExpr, method string) ast.Expr {
+	pos := call.Pos()
+	var t ast.Expr = &ast.Ident{NamePos: pos, Name: "t"}
+	if d.suite {
+		t = &ast.CallExpr{Fun: g.selector(pos, "s", "T"), Lparen: pos, Rparen: pos}
+	}
+	fun := &ast.SelectorExpr{X: t, Sel: &ast.Ident{NamePos: pos, Name: method}}
+	return &ast.CallExpr{Fun: fun, Lparen: call.Lparen, Args: call.Args, Rparen: call.Rparen}
+}
+
+func (g *Ginkgo) selector(pos token.Pos, x, sel string) *ast.SelectorExpr {
+	return &ast.SelectorExpr{X: &ast.Ident{NamePos: pos, Name: x}, Sel: &ast.Ident{NamePos: pos, Name: sel}}
+}
+
+// stmts prints list with the comments of the source around it: the ones before each statement, from the
+// position from on, and the ones after the last one, up to the position to when it is valid. Blank lines
+// between statements are kept.
+func (g *Ginkgo) stmts(d *ginkgoDescribe, list []ast.Stmt, from, to token.Pos) string {
+	var b strings.Builder
+	prev := from
+	for i, stmt := range list {
+		comments := g.comments(d, prev, stmt.Pos())
+		start := stmt.Pos()
+		if len(comments) > 0 {
+			start = comments[0].Pos()
+		}
+		if i > 0 && d.fset.Position(start).Line > d.fset.Position(prev).Line+1 {
+			b.WriteString("\n")
+		}
+		g.writeComments(d, &b, comments, prev, i > 0)
+		b.WriteString(g.print(d, stmt) + "\n")
+		prev = stmt.End()
+	}
+	if to.IsValid() {
+		g.writeComments(d, &b, g.comments(d, prev, to), prev, len(list) > 0)
+	}
+	return b.String()
+}
+
+// comments returns the comments of the file strictly between from and to.
+func (g *Ginkgo) comments(d *ginkgoDescribe, from, to token.Pos) []*ast.Comment {
+	var list []*ast.Comment
+	for _, group := range d.file.AST.Comments {
+		for _, c := range group.List {
+			if c.Pos() > from && c.End() < to {
+				list = append(list, c)
+			}
+		}
+	}
+	return list
+}
+
+// writeComments writes comments on lines of their own, except that one on the line of the statement ending at
+// prev stays at the end of that line. The Arrange, Act, and Assert comments are left out; the test gets new
+// ones.
+func (g *Ginkgo) writeComments(d *ginkgoDescribe, b *strings.Builder, comments []*ast.Comment, prev token.Pos,
+	afterStmt bool) {
+	for _, c := range comments {
+		if aaaComments[strings.TrimSpace(c.Text)] {
+			continue
+		}
+		if afterStmt && d.fset.Position(c.Pos()).Line == d.fset.Position(prev).Line {
+			s := strings.TrimSuffix(b.String(), "\n")
+			b.Reset()
+			b.WriteString(s + " " + c.Text + "\n")
+			continue
+		}
+		b.WriteString(c.Text + "\n")
+	}
+}
+
+// print prints a node of the converted Describe, with its comments. A Ginkgo or Gomega identifier left in it
+// has no testify equivalent and fails the conversion.
+func (g *Ginkgo) print(d *ginkgoDescribe, n ast.Node) string {
+	if name := g.ginkgoName(n); name != "" {
+		g.fail(d, fmt.Errorf("%s has no testify equivalent", name))
+	}
+	var buf bytes.Buffer
+	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	if err := cfg.Fprint(&buf, d.fset, &printer.CommentedNode{Node: n, Comments: d.file.AST.Comments}); err != nil {
+		g.fail(d, err)
+	}
+	return buf.String()
+}
+
+// ginkgoName returns a name of the Ginkgo or Gomega dot imports that node refers to, or "".
+func (g *Ginkgo) ginkgoName(node ast.Node) string {
+	found := ""
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ImportSpec:
+			return false
+		case *ast.SelectorExpr:
+			if found == "" {
+				found = g.ginkgoName(n.X)
+			}
+			return false
+		case *ast.KeyValueExpr:
+			if _, ok := n.Key.(*ast.Ident); ok {
+				if found == "" {
+					found = g.ginkgoName(n.Value)
+				}
+				return false
+			}
+		case *ast.Ident:
+			if ginkgoNames[n.Name] && found == "" {
+				found = n.Name
+			}
+		}
+		return found == ""
+	})
+	return found
+}
+
+// removeBootstrap removes the test functions that call RunSpecs, and the files left with nothing but imports,
+// and returns the changes.
+func (g *Ginkgo) removeBootstrap(pkg *Package, edits map[*File][]sourceEdit) []Change {
+	var changes []Change
+	for _, f := range pkg.Files {
+		for _, decl := range f.AST.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Body == nil {
+				continue
+			}
+			runs := false
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				if call, ok := n.(*ast.CallExpr); ok && g.callee(call) == "RunSpecs" {
+					runs = true
+				}
+				return !runs
+			})
+			if !runs {
+				continue
+			}
+			start := fn.Pos()
+			if fn.Doc != nil {
+				start = fn.Doc.Pos()
+			}
+			edits[f] = append(edits[f], sourceEdit{start: start, end: fn.End()})
+			message := "removed the Ginkgo bootstrap " + fn.Name.Name
+			if g.onlyImports(f, fn) {
+				f.Removed = true
+				message += " and its file, which held nothing else"
+			}
+			changes = append(changes, Change{Pos: pkg.Fset.Position(fn.Pos()), Message: message})
+		}
+	}
+	return changes
+}
+
+func (g *Ginkgo) onlyImports(f *File, keep ast.Decl) bool {
+	for _, decl := range f.AST.Decls {
+		if gen, ok := decl.(*ast.GenDecl); decl != keep && (!ok || gen.Tok != token.IMPORT) {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *Ginkgo) change(d *ginkgoDescribe, pos token.Pos, format string, args ...any) Change {
+	return Change{Pos: d.fset.Position(pos), Message: fmt.Sprintf(format, args...)}
+}
+
+func (g *Ginkgo) fail(d *ginkgoDescribe, err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
@@ -0,0 +1,720 @@
+package codemod
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// gomockModifiers maps the gomock call modifiers that have a testify equivalent to it, for the .On style and
+// the mockery expecter. An empty name means the style has none.
+var gomockModifiers = map[string][2]string{
+	"Return":      {"Return", "Return"},
+	"Times":       {"Times", "Times"},
+	"AnyTimes":    {"Maybe", "Maybe"},
+	"DoAndReturn": {"Return", "RunAndReturn"},
+	"Do":          {"", "Run"},
+}
+
+// gomockCounts are the modifiers that set how many calls an expectation allows.
+var gomockCounts = map[string]bool{"Times": true, "AnyTimes": true, "MinTimes": true, "MaxTimes": true}
+
+// gomockMatchers maps the gomock argument matchers that have a testify equivalent to it. Eq and Nil are
+// dropped for the plain value.
+var gomockMatchers = map[string]string{
+	"Any":                "Anything",
+	"Eq":                 "",
+	"Nil":                "",
+	"Cond":               "MatchedBy",
+	"AssignableToTypeOf": "IsType",
+}
+
+// Gomock migrates gomock tests to mockery mocks and testify mock.
+//
+// gomock.NewController(t) and ctrl.Finish() are removed, and NewMockX(ctrl) takes t instead: a mockery mock
+// asserts its expectations on cleanup. m.EXPECT().Method(args) becomes m.On("Method", args), or stays
+// m.EXPECT().Method(args) when the mocks are generated with the expecter. AnyTimes becomes Maybe, Times(1)
+// Once, DoAndReturn Return (RunAndReturn with the expecter), gomock.Any() mock.Anything, gomock.Eq(v) v, and
+// gomock.Cond mock.MatchedBy. An expectation without a count gets Once, since gomock expects exactly one call
+// by default and testify any number. Mock types and constructors move from the gomock mock packages to the
+// mockery one, suite methods such as TearDownTest that only finished the controller are removed, and
+// //go:generate mockgen directives are removed. Controllers used for anything else, and matchers and modifiers
+// without an equivalent, are reported and left alone.
+type Gomock struct {
+	mocksImportPath string
+	expecter        bool
+	mocked          map[string]bool
+	mockPackages    map[string]bool
+}
+
+// gomockFile is the state of one file being migrated.
+type gomockFile struct {
+	pkg        *Package
+	file       *File
+	gomock     string
+	gomockPath string
+	// oldMocks maps the names of the imported gomock mock packages to their import paths.
+	oldMocks map[string]string
+	// mockIdents and mocksIdents refer to testify mock and to the mockery mocks package; they are named once
+	// the imports are added.
+	mockIdents  []*ast.Ident
+	mocksIdents []*ast.Ident
+	// removed are the statements and fields taken out of the tree, whose lines are closed last.
+	removed []ast.Node
+	// emptied are the blocks removeControllers took the last statement out of.
+	emptied map[*ast.BlockStmt]bool
+	changes []Change
+}
+
+// expectChain is one m.EXPECT().Method(args) call and the modifiers chained on it, outermost first.
+type expectChain struct {
+	recv      ast.Expr
+	expect    *ast.SelectorExpr
+	method    *ast.CallExpr
+	modifiers []*ast.CallExpr
+}
+
+// NewGomock returns the gomock codemod, moving the mocks to mocksImportPath and keeping m.EXPECT() when
+// expecter is set.
+func NewGomock(mocksImportPath string, expecter bool) *Gomock {
+	return &Gomock{
+		mocksImportPath: mocksImportPath,
+		expecter:        expecter,
+		mocked:          map[string]bool{},
+		mockPackages:    map[string]bool{},
+	}
+}
+
+// Mocked returns the names of the interfaces whose gomock mocks the migrated tests construct, sorted.
+func (g *Gomock) Mocked() []string {
+	return g.sorted(g.mocked)
+}
+
+// MockPackages returns the import paths of the gomock mock packages that migrated files stopped importing,
+// sorted.
+func (g *Gomock) MockPackages() []string {
+	return g.sorted(g.mockPackages)
+}
+
+func (g *Gomock) Rewrite(pkg *Package) ([]Change, error) {
+	controllers := g.controllers(pkg)
+	removable, changes := g.removable(pkg, controllers)
+	changes = append(changes, g.dropDirectives(pkg)...)
+	oldMocks := g.oldMockPackages(pkg, controllers)
+	for _, f := range pkg.Files {
+		gf := g.open(pkg, f, oldMocks)
+		if gf == nil {
+			continue
+		}
+		g.rewriteCalls(gf, controllers)
+		g.removeControllers(gf, removable)
+		g.removeEmptyMethods(gf)
+		g.removeFields(gf)
+		g.leftovers(gf)
+		g.fixImports(gf)
+		for _, n := range gf.removed {
+			f.closeGap(pkg.Fset, n)
+		}
+		changes = append(changes, gf.changes...)
+	}
+	return changes, nil
+}
+
+// dropDirectives removes the //go:generate directives that run mockgen, which would bring the gomock mocks
+// back.
+func (g *Gomock) dropDirectives(pkg *Package) []Change {
+	var changes []Change
+	for _, f := range pkg.Files {
+		var removed []ast.Node
+		groups := f.AST.Comments[:0]
+		for _, group := range f.AST.Comments {
+			kept := group.List[:0]
+			for _, c := range group.List {
+				if !strings.HasPrefix(c.Text, "//go:generate ") || !strings.Contains(c.Text, "mockgen") {
+					kept = append(kept, c)
+					continue
+				}
+				msg := "removed the mockgen directive: mockery generates the mocks from .mockery.yaml"
+				changes = append(changes, Change{Pos: pkg.Fset.Position(c.Pos()), Message: msg})
+				removed = append(removed, c)
+			}
+			group.List = kept
+			if len(kept) > 0 {
+				groups = append(groups, group)
+			}
+		}
+		f.AST.Comments = groups
+		g.dropEmptyDocs(f)
+		for _, n := range removed {
+			f.closeGap(pkg.Fset, n)
+		}
+	}
+	return changes
+}
+
+// dropEmptyDocs clears the doc comments dropDirectives emptied.
+func (g *Gomock) dropEmptyDocs(f *File) {
+	ast.Inspect(f.AST, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.GenDecl:
+			if n.Doc != nil && len(n.Doc.List) == 0 {
+				n.Doc = nil
+			}
+		case *ast.FuncDecl:
+			if n.Doc != nil && len(n.Doc.List) == 0 {
+				n.Doc = nil
+			}
+		case *ast.TypeSpec:
+			if n.Doc != nil && len(n.Doc.List) == 0 {
+				n.Doc = nil
+			}
+		}
+		return true
+	})
+}
+
+// open returns the migration state of f, or nil when f does not import gomock.
+func (g *Gomock) open(pkg *Package, f *File, oldMocks map[string]bool) *gomockFile {
+	gf := &gomockFile{pkg: pkg, file: f, oldMocks: map[string]string{}, emptied: map[*ast.BlockStmt]bool{}}
+	for _, p := range []string{gomockUber, gomockGolang} {
+		if name, ok := f.importName(p); ok {
+			gf.gomock, gf.gomockPath = name, p
+		}
+	}
+	if gf.gomock == "" {
+		return nil
+	}
+	for p := range oldMocks {
+		if name, ok := f.importName(p); ok {
+			gf.oldMocks[name] = p
+		}
+	}
+	return gf
+}
+
+// controllers maps every expression assigned gomock.NewController(t) in pkg, such as ctrl or s.ctrl, to t.
+func (g *Gomock) controllers(pkg *Package) map[string]ast.Expr {
+	controllers := map[string]ast.Expr{}
+	for _, f := range pkg.Files {
+		gf := g.open(pkg, f, nil)
+		if gf == nil {
+			continue
+		}
+		ast.Inspect(f.AST, func(n ast.Node) bool {
+			if assign, ok := n.(*ast.AssignStmt); ok && len(assign.Lhs) == 1 && len(assign.Rhs) == 1 {
+				if t := g.newController(gf, assign.Rhs[0]); t != nil {
+					controllers[types.ExprString(assign.Lhs[0])] = t
+				}
+			}
+			return true
+		})
+	}
+	return controllers
+}
+
+// newController returns t for gomock.NewController(t), or nil.
+func (g *Gomock) newController(gf *gomockFile, e ast.Expr) ast.Expr {
+	call, ok := e.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 || g.gomockName(gf, call.Fun) != "NewController" {
+		return nil
+	}
+	return call.Args[0]
+}
+
+// gomockName returns Name for gomock.Name, or "".
+func (g *Gomock) gomockName(gf *gomockFile, e ast.Expr) string {
+	sel, ok := e.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	if x, ok := sel.X.(*ast.Ident); ok && x.Name == gf.gomock {
+		return sel.Sel.Name
+	}
+	return ""
+}
+
+// removable returns the controllers used only in their assignment, in Finish calls, and as the argument of
+// NewMock constructors, and reports the others.
+func (g *Gomock) removable(pkg *Package, controllers map[string]ast.Expr) (map[string]bool, []Change) {
+	allowed := map[token.Pos]bool{}
+	for _, f := range pkg.Files {
+		ast.Inspect(f.AST, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				if len(n.Lhs) == 1 && len(n.Rhs) == 1 && g.callsNewController(n.Rhs[0]) {
+					allowed[n.Lhs[0].Pos()] = true
+				}
+			case *ast.ExprStmt, *ast.DeferStmt:
+				if ctrl := g.finish(n.(ast.Stmt)); ctrl != nil {
+					allowed[ctrl.Pos()] = true
+				}
+			case *ast.CallExpr:
+				if sel, ok := n.Fun.(*ast.SelectorExpr); ok && strings.HasPrefix(sel.Sel.Name, "NewMock") &&
+					len(n.Args) == 1 {
+					allowed[n.Args[0].Pos()] = true
+				}
+			}
+			return true
+		})
+	}
+
+	used := map[string]token.Pos{}
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Field:
+			ast.Inspect(n.Type, visit)
+			return false
+		case *ast.Ident, *ast.SelectorExpr:
+			key := types.ExprString(n.(ast.Expr))
+			if _, ok := controllers[key]; ok && !allowed[n.Pos()] && !used[key].IsValid() {
+				used[key] = n.Pos()
+			}
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				ast.Inspect(sel.X, visit)
+				return false
+			}
+		}
+		return true
+	}
+	for _, f := range pkg.Files {
+		ast.Inspect(f.AST, visit)
+	}
+
+	removable := map[string]bool{}
+	var changes []Change
+	for key := range controllers {
+		pos, ok := used[key]
+		if !ok {
+			removable[key] = true
+			continue
+		}
+		msg := fmt.Sprintf("controller %s is used beyond NewMock and Finish", key)
+		changes = append(changes, Change{Pos: pkg.Fset.Position(pos), Message: msg, Skipped: true})
+	}
+	return removable, changes
+}
+
+func (g *Gomock) callsNewController(e ast.Expr) bool {
+	call, ok := e.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "NewController"
+}
+
+// finish returns ctrl for the statements ctrl.Finish(), defer ctrl.Finish(), and t.Cleanup(ctrl.Finish), or
+// nil.
+func (g *Gomock) finish(stmt ast.Stmt) ast.Expr {
+	var call *ast.CallExpr
+	switch stmt := stmt.(type) {
+	case *ast.ExprStmt:
+		call, _ = stmt.X.(*ast.CallExpr)
+	case *ast.DeferStmt:
+		call = stmt.Call
+	}
+	if call == nil {
+		return nil
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	if sel.Sel.Name == "Finish" && len(call.Args) == 0 {
+		return sel.X
+	}
+	if sel.Sel.Name == "Cleanup" && len(call.Args) == 1 {
+		if arg, ok := call.Args[0].(*ast.SelectorExpr); ok && arg.Sel.Name == "Finish" {
+			return arg.X
+		}
+	}
+	return nil
+}
+
+// oldMockPackages returns the import paths of the packages whose NewMock constructors take a controller.
+func (g *Gomock) oldMockPackages(pkg *Package, controllers map[string]ast.Expr) map[string]bool {
+	paths := map[string]bool{}
+	for _, f := range pkg.Files {
+		gf := g.open(pkg, f, nil)
+		if gf == nil {
+			continue
+		}
+		names := map[string]string{}
+		for _, spec := range f.AST.Imports {
+			p, _ := strconv.Unquote(spec.Path.Value)
+			if name, ok := f.importName(p); ok {
+				names[name] = p
+			}
+		}
+		ast.Inspect(f.AST, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || g.controllerArg(gf, call, controllers) == nil {
+				return true
+			}
+			x := call.Fun.(*ast.SelectorExpr).X.(*ast.Ident)
+			if p := names[x.Name]; p != "" && p != g.mocksImportPath {
+				paths[p] = true
+			}
+			return true
+		})
+	}
+	return paths
+}
+
+// controllerArg returns t when call is pkg.NewMockX(ctrl) for a controller created from t, or nil.
+func (g *Gomock) controllerArg(gf *gomockFile, call *ast.CallExpr, controllers map[string]ast.Expr) ast.Expr {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !strings.HasPrefix(sel.Sel.Name, "NewMock") || len(call.Args) != 1 {
+		return nil
+	}
+	if _, ok := sel.X.(*ast.Ident); !ok {
+		return nil
+	}
+	if t := g.newController(gf, call.Args[0]); t != nil {
+		return t
+	}
+	return controllers[types.ExprString(call.Args[0])]
+}
+
+// rewriteCalls migrates the NewMock constructors, EXPECT chains, gomock.InOrder calls, and references to
+// the gomock mock packages of one file.
+func (g *Gomock) rewriteCalls(gf *gomockFile, controllers map[string]ast.Expr) {
+	ast.Inspect(gf.file.AST, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if chain := g.chain(call); chain != nil {
+			g.rewriteChain(gf, chain)
+			return false
+		}
+		if t := g.controllerArg(gf, call, controllers); t != nil {
+			sel := call.Fun.(*ast.SelectorExpr)
+			g.mocked[strings.TrimPrefix(sel.Sel.Name, "NewMock")] = true
+			call.Args[0] = g.clone(t, call.Args[0].Pos())
+			msg := fmt.Sprintf("passed %s to %s instead of a controller", types.ExprString(t), sel.Sel.Name)
+			gf.changes = append(gf.changes, g.change(gf, call.Pos(), msg))
+		}
+		if g.gomockName(gf, call.Fun) == "InOrder" && !g.expecter {
+			call.Fun = g.mockSelector(gf, "InOrder", call.Fun.Pos())
+			gf.changes = append(gf.changes, g.change(gf, call.Pos(), "replaced gomock.InOrder with mock.InOrder"))
+		}
+		return true
+	})
+
+	ast.Inspect(gf.file.AST, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if x, ok := sel.X.(*ast.Ident); ok && gf.oldMocks[x.Name] != "" {
+			id := &ast.Ident{NamePos: x.Pos()}
+			gf.mocksIdents = append(gf.mocksIdents, id)
+			sel.X = id
+		}
+		return true
+	})
+}
+
+// chain returns the EXPECT chain call ends, or nil.
+func (g *Gomock) chain(call *ast.CallExpr) *expectChain {
+	var modifiers []*ast.CallExpr
+	for c := call; ; {
+		sel, ok := c.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return nil
+		}
+		inner, ok := sel.X.(*ast.CallExpr)
+		if !ok {
+			return nil
+		}
+		if expect, ok := inner.Fun.(*ast.SelectorExpr); ok && expect.Sel.Name == "EXPECT" && len(inner.Args) == 0 {
+			return &expectChain{recv: expect.X, expect: expect, method: c, modifiers: modifiers}
+		}
+		modifiers = append(modifiers, c)
+		c = inner
+	}
+}
+
+func (g *Gomock) rewriteChain(gf *gomockFile, ch *expectChain) {
+	method := ch.method.Fun.(*ast.SelectorExpr).Sel
+	name := fmt.Sprintf("%s.EXPECT().%s", types.ExprString(ch.recv), method.Name)
+	if reason := g.unsupported(gf, ch); reason != "" {
+		msg := fmt.Sprintf("%s: %s has no testify equivalent", name, reason)
+		gf.changes = append(gf.changes, Change{Pos: gf.pkg.Fset.Position(ch.method.Pos()), Message: msg, Skipped: true})
+		return
+	}
+
+	style := 0
+	if g.expecter {
+		style = 1
+	}
+	counted := false
+	for _, m := range ch.modifiers {
+		sel := m.Fun.(*ast.SelectorExpr)
+		counted = counted || gomockCounts[sel.Sel.Name]
+		sel.Sel.Name = gomockModifiers[sel.Sel.Name][style]
+		if sel.Sel.Name == "Times" && len(m.Args) == 1 {
+			if lit, ok := m.Args[0].(*ast.BasicLit); ok && (lit.Value == "1" || lit.Value == "2") {
+				sel.Sel.Name = map[string]string{"1": "Once", "2": "Twice"}[lit.Value]
+				m.Args = nil
+			}
+		}
+	}
+	args := make([]ast.Expr, 0, len(ch.method.Args)+1)
+	if !g.expecter {
+		args = append(args, &ast.BasicLit{ValuePos: method.Pos(), Kind: token.STRING, Value: strconv.Quote(method.Name)})
+	}
+	for _, arg := range ch.method.Args {
+		args = append(args, g.matcher(gf, arg))
+	}
+	ch.method.Args = args
+
+	msg := fmt.Sprintf("migrated %s to the mockery expecter", name)
+	if !g.expecter {
+		ch.method.Fun = &ast.SelectorExpr{X: ch.recv, Sel: &ast.Ident{NamePos: ch.expect.Sel.Pos(), Name: "On"}}
+		msg = fmt.Sprintf("replaced %s with %s.On(%q)", name, types.ExprString(ch.recv), method.Name)
+	}
+	if !counted {
+		// The outermost call becomes the receiver of Once, in place, as its parent holds it by pointer.
+		outer := ch.method
+		if len(ch.modifiers) > 0 {
+			outer = ch.modifiers[0]
+		}
+		inner := *outer
+		once := &ast.SelectorExpr{X: &inner, Sel: &ast.Ident{NamePos: outer.Rparen, Name: "Once"}}
+		*outer = ast.CallExpr{Fun: once, Lparen: outer.Rparen, Rparen: outer.Rparen}
+		msg += ", called once as gomock expected"
+	}
+	gf.changes = append(gf.changes, g.change(gf, ch.method.Pos(), msg))
+}
+
+// unsupported returns the first modifier or matcher of ch without a testify equivalent, or "".
+func (g *Gomock) unsupported(gf *gomockFile, ch *expectChain) string {
+	style := 0
+	if g.expecter {
+		style = 1
+	}
+	for _, m := range ch.modifiers {
+		name := m.Fun.(*ast.SelectorExpr).Sel.Name
+		if gomockModifiers[name][style] == "" {
+			return name
+		}
+	}
+	for _, arg := range ch.method.Args {
+		call, ok := arg.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		name := g.gomockName(gf, call.Fun)
+		if _, ok := gomockMatchers[name]; name != "" && !ok {
+			return "gomock." + name
+		}
+	}
+	return ""
+}
+
+// matcher returns the testify argument for a gomock matcher, and arg itself for plain values.
+func (g *Gomock) matcher(gf *gomockFile, arg ast.Expr) ast.Expr {
+	call, ok := arg.(*ast.CallExpr)
+	if !ok {
+		return arg
+	}
+	switch name := g.gomockName(gf, call.Fun); name {
+	case "Any":
+		return g.mockSelector(gf, gomockMatchers[name], call.Pos())
+	case "Eq":
+		return call.Args[0]
+	case "Nil":
+		return &ast.Ident{NamePos: call.Pos(), Name: "nil"}
+	case "Cond", "AssignableToTypeOf":
+		call.Fun = g.mockSelector(gf, gomockMatchers[name], call.Pos())
+	}
+	return call
+}
+
+// mockSelector returns mock.name, with the package name filled in once the import is added.
+func (g *Gomock) mockSelector(gf *gomockFile, name string, pos token.Pos) *ast.SelectorExpr {
+	id := &ast.Ident{NamePos: pos}
+	gf.mockIdents = append(gf.mockIdents, id)
+	return &ast.SelectorExpr{X: id, Sel: &ast.Ident{NamePos: pos, Name: name}}
+}
+
+// removeControllers deletes the NewController assignments and Finish calls of the removable controllers.
+func (g *Gomock) removeControllers(gf *gomockFile, removable map[string]bool) {
+	ast.Inspect(gf.file.AST, func(n ast.Node) bool {
+		block, ok := n.(*ast.BlockStmt)
+		if !ok {
+			return true
+		}
+		kept := block.List[:0]
+		for _, stmt := range block.List {
+			if msg := g.controllerStmt(gf, stmt, removable); msg != "" {
+				gf.changes = append(gf.changes, g.change(gf, stmt.Pos(), msg))
+				gf.removed = append(gf.removed, stmt)
+				continue
+			}
+			kept = append(kept, stmt)
+		}
+		if len(kept) == 0 && len(block.List) > 0 {
+			gf.emptied[block] = true
+		}
+		block.List = kept
+		return true
+	})
+}
+
+// removeEmptyMethods deletes the methods removeControllers emptied, such as a TearDownTest that only called
+// Finish.
+func (g *Gomock) removeEmptyMethods(gf *gomockFile) {
+	kept := gf.file.AST.Decls[:0]
+	for _, decl := range gf.file.AST.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || !gf.emptied[fn.Body] {
+			kept = append(kept, decl)
+			continue
+		}
+		gf.changes = append(gf.changes, g.change(gf, fn.Pos(), "removed "+fn.Name.Name+": it only managed the controller"))
+		gf.removed = append(gf.removed, fn)
+	}
+	gf.file.AST.Decls = kept
+}
+
+// controllerStmt returns what removing stmt does when it creates or finishes a removable controller, or "".
+func (g *Gomock) controllerStmt(gf *gomockFile, stmt ast.Stmt, removable map[string]bool) string {
+	if assign, ok := stmt.(*ast.AssignStmt); ok && len(assign.Lhs) == 1 && len(assign.Rhs) == 1 {
+		key := types.ExprString(assign.Lhs[0])
+		if removable[key] && g.newController(gf, assign.Rhs[0]) != nil {
+			return "removed the gomock controller " + key
+		}
+	}
+	if ctrl := g.finish(stmt); ctrl != nil && removable[types.ExprString(ctrl)] {
+		return fmt.Sprintf("removed %s.Finish(): mockery mocks assert their expectations on cleanup", types.ExprString(ctrl))
+	}
+	return ""
+}
+
+// removeFields deletes struct fields of type *gomock.Controller that nothing refers to any more.
+func (g *Gomock) removeFields(gf *gomockFile) {
+	ast.Inspect(gf.file.AST, func(n ast.Node) bool {
+		st, ok := n.(*ast.StructType)
+		if !ok {
+			return true
+		}
+		kept := st.Fields.List[:0]
+		for _, field := range st.Fields.List {
+			star, ok := field.Type.(*ast.StarExpr)
+			if !ok || g.gomockName(gf, star.X) != "Controller" || len(field.Names) == 0 || g.referenced(gf, field) {
+				kept = append(kept, field)
+				continue
+			}
+			for _, name := range field.Names {
+				gf.changes = append(gf.changes, g.change(gf, name.Pos(), "removed the controller field "+name.Name))
+			}
+			gf.removed = append(gf.removed, field)
+		}
+		st.Fields.List = kept
+		return true
+	})
+}
+
+// referenced reports whether a selector or composite literal key anywhere in the package names a field.
+func (g *Gomock) referenced(gf *gomockFile, field *ast.Field) bool {
+	names := map[string]bool{}
+	for _, name := range field.Names {
+		names[name.Name] = true
+	}
+	found := false
+	for _, f := range gf.pkg.Files {
+		ast.Inspect(f.AST, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.SelectorExpr:
+				found = found || names[n.Sel.Name]
+			case *ast.KeyValueExpr:
+				if key, ok := n.Key.(*ast.Ident); ok {
+					found = found || names[key.Name]
+				}
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+// fixImports drops the gomock and gomock mock package imports nothing uses any more, and adds testify mock
+// and the mockery mocks package for the references the migration created.
+func (g *Gomock) fixImports(gf *gomockFile) {
+	fset := gf.pkg.Fset
+	for _, p := range gf.oldMocks {
+		if gf.file.dropImport(fset, p) {
+			g.mockPackages[p] = true
+		}
+	}
+	gf.file.dropImport(fset, gf.gomockPath)
+	if len(gf.mocksIdents) > 0 {
+		name := gf.file.addImport(fset, g.mocksImportPath)
+		for _, id := range gf.mocksIdents {
+			id.Name = name
+		}
+	}
+	if len(gf.mockIdents) > 0 {
+		name := gf.file.addImport(fset, testifyMock)
+		for _, id := range gf.mockIdents {
+			id.Name = name
+		}
+	}
+}
+
+// leftovers reports every gomock reference the migration could not remove, except on lines already reported.
+func (g *Gomock) leftovers(gf *gomockFile) {
+	reported := map[int]bool{}
+	for _, c := range gf.changes {
+		if c.Skipped {
+			reported[c.Pos.Line] = true
+		}
+	}
+	ast.Inspect(gf.file.AST, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if ok && g.gomockName(gf, sel) != "" && !reported[gf.pkg.Fset.Position(sel.Pos()).Line] {
+			name := sel.Sel.Name
+			msg := fmt.Sprintf("gomock.%s is still used; migrate it by hand", name)
+			gf.changes = append(gf.changes, Change{Pos: gf.pkg.Fset.Position(n.Pos()), Message: msg, Skipped: true})
+		}
+		return true
+	})
+}
+
+// clone copies t, an identifier, selector, or call such as s.T(), placed at pos.
+func (g *Gomock) clone(e ast.Expr, pos token.Pos) ast.Expr {
+	switch e := e.(type) {
+	case *ast.Ident:
+		return &ast.Ident{NamePos: pos, Name: e.Name}
+	case *ast.SelectorExpr:
+		return &ast.SelectorExpr{X: g.clone(e.X, pos), Sel: &ast.Ident{NamePos: pos, Name: e.Sel.Name}}
+	case *ast.CallExpr:
+		args := make([]ast.Expr, len(e.Args))
+		for i, arg := range e.Args {
+			args[i] = g.clone(arg, pos)
+		}
+		return &ast.CallExpr{Fun: g.clone(e.Fun, pos), Lparen: pos, Args: args, Rparen: pos}
+	}
+	return e
+}
+
+func (g *Gomock) change(gf *gomockFile, pos token.Pos, msg string) Change {
+	return Change{Pos: gf.pkg.Fset.Position(pos), Message: msg}
+}
+
+func (g *Gomock) sorted(set map[string]bool) []string {
+	list := make([]string, 0, len(set))
+	for s := range set {
+		list = append(list, s)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,15 @@
+package codemod_test
+
+import (
+	"testing"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/codemod"
+)
+
+func TestGomock_Rewrite_OnStyle_MatchGolden(t *testing.T) {
+	matchGolden(t, codemod.NewGomock("example.com/app/test/mocks", false), "gomock/on")
+}
+
+func TestGomock_Rewrite_ExpecterStyle_MatchGolden(t *testing.T) {
+	matchGolden(t, codemod.NewGomock("example.com/app/test/mocks", true), "gomock/expecter")
+}
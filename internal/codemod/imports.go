@@ -0,0 +1,171 @@
+package codemod
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Import paths the codemods read and write.
+const (
+	testifyAssert  = "github.com/stretchr/testify/assert"
+	testifyRequire = "github.com/stretchr/testify/require"
+	testifySuite   = "github.com/stretchr/testify/suite"
+	testifyMock    = "github.com/stretchr/testify/mock"
+	gomockUber     = "go.uber.org/mock/gomock"
+	gomockGolang   = "github.com/golang/mock/gomock"
+	ginkgoV2       = "github.com/onsi/ginkgo/v2"
+	ginkgoV1       = "github.com/onsi/ginkgo"
+	gomega         = "github.com/onsi/gomega"
+)
+
+// importName returns the name f uses for an import path, and false when f does not import it.
+func (f *File) importName(importPath string) (string, bool) {
+	for _, spec := range f.AST.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || p != importPath {
+			continue
+		}
+		if spec.Name != nil {
+			return spec.Name.Name, true
+		}
+		return path.Base(importPath), true
+	}
+	return "", false
+}
+
+// addImport imports importPath unless f already does, and returns the name to refer to it by. A new import
+// joins the group of the first import declaration whose paths share the longest prefix with it, a standard
+// library import the group of the other standard library imports, or else the last group, sorted.
+func (f *File) addImport(fset *token.FileSet, importPath string) string {
+	if name, ok := f.importName(importPath); ok {
+		return name
+	}
+	spec := &ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(importPath)}}
+	f.AST.Imports = append(f.AST.Imports, spec)
+	for _, decl := range f.AST.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		last := gen.Specs[len(gen.Specs)-1].(*ast.ImportSpec)
+		at, best, standard := len(gen.Specs)-1, 0, false
+		for i, s := range gen.Specs {
+			p, _ := strconv.Unquote(s.(*ast.ImportSpec).Path.Value)
+			n := f.sharedSegments(p, importPath)
+			if f.standard(p) && f.standard(importPath) {
+				n, standard = max(n, 1), true
+			}
+			if n > best {
+				at, best = i, n
+			}
+		}
+		if !gen.Lparen.IsValid() {
+			gen.Lparen, gen.Rparen = last.Pos(), last.End()
+		}
+		if f.standard(importPath) && !standard {
+			// The first standard library import opens a group of its own: a line well above the others
+			// separates it.
+			spec.Path.ValuePos, spec.EndPos = f.AST.Name.Pos(), f.AST.Name.Pos()
+			gen.Specs = append([]ast.Spec{spec}, gen.Specs...)
+			ast.SortImports(fset, f.AST)
+			return path.Base(importPath)
+		}
+		// SortImports groups specs by line, so the new one takes the line of its neighbour and follows it. Its
+		// end stays within the neighbour too, which may end the file.
+		spec.Path.ValuePos, spec.EndPos = gen.Specs[at].Pos(), gen.Specs[at].End()
+		gen.Specs = append(gen.Specs[:at+1], append([]ast.Spec{spec}, gen.Specs[at+1:]...)...)
+		ast.SortImports(fset, f.AST)
+		return path.Base(importPath)
+	}
+	spec.Path.ValuePos = f.AST.Name.End()
+	gen := &ast.GenDecl{TokPos: f.AST.Name.End(), Tok: token.IMPORT, Specs: []ast.Spec{spec}}
+	f.AST.Decls = append([]ast.Decl{gen}, f.AST.Decls...)
+	return path.Base(importPath)
+}
+
+// dropImport removes the import of importPath when no selector in f refers to it any more, and reports
+// whether it did.
+func (f *File) dropImport(fset *token.FileSet, importPath string) bool {
+	name, ok := f.importName(importPath)
+	if !ok || name == "_" || name == "." {
+		return false
+	}
+	used := false
+	ast.Inspect(f.AST, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok && x.Name == name {
+				used = true
+			}
+		}
+		return !used
+	})
+	if used {
+		return false
+	}
+	return f.deleteImport(fset, importPath)
+}
+
+// deleteImport removes the import of importPath, used or not, and reports whether f had it.
+func (f *File) deleteImport(fset *token.FileSet, importPath string) bool {
+	for i, decl := range f.AST.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		for j, spec := range gen.Specs {
+			if p, _ := strconv.Unquote(spec.(*ast.ImportSpec).Path.Value); p != importPath {
+				continue
+			}
+			gen.Specs = append(gen.Specs[:j], gen.Specs[j+1:]...)
+			switch {
+			case len(gen.Specs) == 0:
+				f.AST.Decls = append(f.AST.Decls[:i], f.AST.Decls[i+1:]...)
+			case gen.Lparen.IsValid() && !f.sharesLine(fset, gen, spec):
+				// Otherwise the emptied line splits the import group.
+				f.closeGap(fset, spec)
+			}
+			f.removeImportSpec(importPath)
+			return true
+		}
+	}
+	return false
+}
+
+// sharesLine reports whether a spec of gen is on the line of spec, as imports added by addImport are.
+func (f *File) sharesLine(fset *token.FileSet, gen *ast.GenDecl, spec ast.Spec) bool {
+	line := fset.Position(spec.Pos()).Line
+	for _, s := range gen.Specs {
+		if fset.Position(s.Pos()).Line == line {
+			return true
+		}
+	}
+	return false
+}
+
+// sharedSegments counts the leading path elements a and b have in common.
+func (f *File) sharedSegments(a, b string) int {
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	n := 0
+	for n < len(as) && n < len(bs) && as[n] == bs[n] {
+		n++
+	}
+	return n
+}
+
+// standard reports whether importPath belongs to the standard library, whose first element has no dot.
+func (f *File) standard(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+func (f *File) removeImportSpec(importPath string) {
+	for i, spec := range f.AST.Imports {
+		if p, _ := strconv.Unquote(spec.Path.Value); p == importPath {
+			f.AST.Imports = append(f.AST.Imports[:i], f.AST.Imports[i+1:]...)
+			return
+		}
+	}
+}
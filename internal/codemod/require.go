@@ -0,0 +1,378 @@
+package codemod
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// errorChecks always stop the test: nothing after a failed error check is meaningful.
+var errorChecks = map[string]bool{
+	"Error": true, "ErrorAs": true, "ErrorContains": true, "ErrorIs": true, "EqualError": true, "NoError": true,
+}
+
+// guards are preconditions when a later statement uses the value they check.
+var guards = map[string]bool{
+	"Implements": true, "IsType": true, "Len": true, "NotEmpty": true, "NotNil": true, "NotZero": true,
+	"True": true, "False": true,
+}
+
+// assertForm is how an assertion call is spelled.
+type assertForm int
+
+const (
+	formNone assertForm = iota
+	// formPackage is assert.X(t, ...).
+	formPackage
+	// formSuite is s.X(...) on a suite receiver.
+	formSuite
+	// formAssertChain is s.Assert().X(...).
+	formAssertChain
+)
+
+// Require applies the go-unit-tests assertion policy: Require() for preconditions and error checks, plain
+// assertions for value comparisons.
+//
+// Error checks (NoError, Error, ErrorIs, ErrorAs, ErrorContains, EqualError) become require.X or
+// s.Require().X. Guards (NotNil, NotEmpty, Len, True, False, NotZero, IsType, Implements) do too when a later
+// statement of the same block uses the value they check, or a value assigned alongside it, as in got, err :=.
+// Remaining s.Assert().X chains become s.X. Assertions inside function literals other than t.Run and s.Run
+// subtests may run on another goroutine, where require cannot stop the test; they are reported and left alone.
+type Require struct{}
+
+// requireFile is the state of one file being rewritten.
+type requireFile struct {
+	pkg     *Package
+	suites  map[string]bool
+	assert  string
+	require string
+	// recv is the receiver name of the suite method being walked, or "".
+	recv    string
+	locals  map[string]bool
+	renames []*ast.SelectorExpr
+	changes []Change
+}
+
+// NewRequire returns the require codemod.
+func NewRequire() *Require {
+	return &Require{}
+}
+
+func (r *Require) Rewrite(pkg *Package) ([]Change, error) {
+	var changes []Change
+	suites := r.suiteTypes(pkg)
+	for _, f := range pkg.Files {
+		if !f.Test {
+			continue
+		}
+		rf := &requireFile{pkg: pkg, suites: suites, require: "require"}
+		rf.assert, _ = f.importName(testifyAssert)
+		if name, ok := f.importName(testifyRequire); ok {
+			rf.require = name
+		}
+		for _, decl := range f.AST.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			rf.recv = r.suiteReceiver(rf, fn)
+			rf.locals = r.locals(fn)
+			r.stmts(rf, fn.Body.List, true)
+		}
+		if len(rf.renames) > 0 {
+			name := f.addImport(pkg.Fset, testifyRequire)
+			for _, sel := range rf.renames {
+				sel.X = &ast.Ident{NamePos: sel.X.Pos(), Name: name}
+			}
+			f.dropImport(pkg.Fset, testifyAssert)
+		}
+		changes = append(changes, rf.changes...)
+	}
+	return changes, nil
+}
+
+// suiteTypes returns the names of the struct types in pkg that embed suite.Suite.
+func (r *Require) suiteTypes(pkg *Package) map[string]bool {
+	suites := map[string]bool{}
+	for _, f := range pkg.Files {
+		name, ok := f.importName(testifySuite)
+		if !ok {
+			continue
+		}
+		ast.Inspect(f.AST, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			st, ok := spec.Type.(*ast.StructType)
+			if !ok {
+				return false
+			}
+			for _, field := range st.Fields.List {
+				typ := field.Type
+				if star, ok := typ.(*ast.StarExpr); ok {
+					typ = star.X
+				}
+				sel, ok := typ.(*ast.SelectorExpr)
+				if len(field.Names) > 0 || !ok || sel.Sel.Name != "Suite" {
+					continue
+				}
+				if x, ok := sel.X.(*ast.Ident); ok && x.Name == name {
+					suites[spec.Name.Name] = true
+				}
+			}
+			return false
+		})
+	}
+	return suites
+}
+
+// suiteReceiver returns the receiver name of fn when fn is a method of a suite type, or "".
+func (r *Require) suiteReceiver(rf *requireFile, fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 || len(fn.Recv.List[0].Names) == 0 {
+		return ""
+	}
+	typ := fn.Recv.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	if id, ok := typ.(*ast.Ident); ok && rf.suites[id.Name] {
+		return fn.Recv.List[0].Names[0].Name
+	}
+	return ""
+}
+
+// locals returns the names fn declares: parameters, := and var declarations, and function literal parameters.
+func (r *Require) locals(fn *ast.FuncDecl) map[string]bool {
+	locals := map[string]bool{}
+	addFields := func(fields *ast.FieldList) {
+		if fields == nil {
+			return
+		}
+		for _, field := range fields.List {
+			for _, name := range field.Names {
+				locals[name.Name] = true
+			}
+		}
+	}
+	addFields(fn.Type.Params)
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if n.Tok != token.DEFINE {
+				return true
+			}
+			for _, lhs := range n.Lhs {
+				if id, ok := lhs.(*ast.Ident); ok && id.Name != "_" {
+					locals[id.Name] = true
+				}
+			}
+		case *ast.ValueSpec:
+			for _, name := range n.Names {
+				locals[name.Name] = true
+			}
+		case *ast.FuncLit:
+			addFields(n.Type.Params)
+		}
+		return true
+	})
+	return locals
+}
+
+// stmts rewrites the assertions of one statement list and of the lists nested in it. direct is false inside
+// function literals that are not subtests.
+func (r *Require) stmts(rf *requireFile, list []ast.Stmt, direct bool) {
+	for i, stmt := range list {
+		if expr, ok := stmt.(*ast.ExprStmt); ok {
+			if call, ok := expr.X.(*ast.CallExpr); ok {
+				r.call(rf, list, i, call, direct)
+			}
+		}
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.BlockStmt:
+				r.stmts(rf, n.List, direct)
+				return false
+			case *ast.CaseClause:
+				r.stmts(rf, n.Body, direct)
+				return false
+			case *ast.CommClause:
+				r.stmts(rf, n.Body, direct)
+				return false
+			case *ast.FuncLit:
+				r.stmts(rf, n.Body.List, false)
+				return false
+			case *ast.CallExpr:
+				if lit := r.subtest(n); lit != nil {
+					r.stmts(rf, lit.Body.List, direct)
+					return false
+				}
+			}
+			return true
+		})
+	}
+}
+
+// subtest returns the body of t.Run(name, func(t *testing.T) {...}) or s.Run(name, func() {...}), or nil.
+func (r *Require) subtest(call *ast.CallExpr) *ast.FuncLit {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Run" || len(call.Args) != 2 {
+		return nil
+	}
+	lit, _ := call.Args[1].(*ast.FuncLit)
+	return lit
+}
+
+// call rewrites one assertion statement, list[i].
+func (r *Require) call(rf *requireFile, list []ast.Stmt, i int, call *ast.CallExpr, direct bool) {
+	form, name := r.classify(rf, call)
+	if form == formNone {
+		return
+	}
+	sel := call.Fun.(*ast.SelectorExpr)
+	args := call.Args
+	if form == formPackage {
+		args = args[1:]
+	}
+	required := errorChecks[name] || guards[name] && r.usedBelow(rf, list, i, r.checked(name, args))
+	pos := rf.pkg.Fset.Position(call.Pos())
+
+	if !required {
+		if form == formAssertChain {
+			chain := sel.X.(*ast.CallExpr).Fun.(*ast.SelectorExpr)
+			msg := fmt.Sprintf("replaced %s.Assert().%s with %s.%s", rf.recv, name, rf.recv, name)
+			sel.X = chain.X
+			rf.changes = append(rf.changes, Change{Pos: pos, Message: msg})
+		}
+		return
+	}
+	from := r.spelling(rf, form, name)
+	to := rf.recv + ".Require()." + name
+	if form == formPackage {
+		to = rf.require + "." + name
+	}
+	if !direct {
+		msg := fmt.Sprintf("%s inside a function literal: require cannot stop the test from another goroutine", from)
+		rf.changes = append(rf.changes, Change{Pos: pos, Message: msg, Skipped: true})
+		return
+	}
+
+	switch form {
+	case formPackage:
+		rf.renames = append(rf.renames, sel)
+	case formSuite:
+		sel.X = &ast.CallExpr{
+			Fun:    &ast.SelectorExpr{X: sel.X, Sel: &ast.Ident{NamePos: sel.Sel.Pos(), Name: "Require"}},
+			Lparen: sel.Sel.Pos(),
+			Rparen: sel.Sel.Pos(),
+		}
+	case formAssertChain:
+		sel.X.(*ast.CallExpr).Fun.(*ast.SelectorExpr).Sel.Name = "Require"
+	}
+	rf.changes = append(rf.changes, Change{Pos: pos, Message: fmt.Sprintf("replaced %s with %s", from, to)})
+}
+
+// classify returns how call spells an assertion and the assertion name, or formNone when it is not one of
+// the assertions this codemod rewrites.
+func (r *Require) classify(rf *requireFile, call *ast.CallExpr) (assertForm, string) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !assertions[sel.Sel.Name] {
+		return formNone, ""
+	}
+	name := sel.Sel.Name
+	switch x := sel.X.(type) {
+	case *ast.Ident:
+		if rf.assert != "" && x.Name == rf.assert && len(call.Args) > 0 {
+			return formPackage, name
+		}
+		if rf.recv != "" && x.Name == rf.recv {
+			return formSuite, name
+		}
+	case *ast.CallExpr:
+		chain, ok := x.Fun.(*ast.SelectorExpr)
+		if !ok || chain.Sel.Name != "Assert" || len(x.Args) != 0 {
+			return formNone, ""
+		}
+		if recv, ok := chain.X.(*ast.Ident); ok && rf.recv != "" && recv.Name == rf.recv {
+			return formAssertChain, name
+		}
+	}
+	return formNone, ""
+}
+
+// checked returns the argument a guard checks: the object for IsType and Implements, the first otherwise.
+func (r *Require) checked(name string, args []ast.Expr) ast.Expr {
+	i := 0
+	if name == "IsType" || name == "Implements" {
+		i = 1
+	}
+	if i >= len(args) {
+		return nil
+	}
+	return args[i]
+}
+
+// usedBelow reports whether a statement after list[i] uses the value checked, a local variable in it, or a
+// variable assigned in the same statement as one of those.
+func (r *Require) usedBelow(rf *requireFile, list []ast.Stmt, i int, checked ast.Expr) bool {
+	if checked == nil {
+		return false
+	}
+	targets := map[string]bool{types.ExprString(checked): true}
+	ast.Inspect(checked, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && rf.locals[id.Name] {
+			targets[id.Name] = true
+		}
+		return true
+	})
+	for j := i - 1; j >= 0; j-- {
+		assign, ok := list[j].(*ast.AssignStmt)
+		if !ok || !r.assigns(assign, targets) {
+			continue
+		}
+		for _, lhs := range assign.Lhs {
+			if id, ok := lhs.(*ast.Ident); ok && id.Name != "_" {
+				targets[id.Name] = true
+			}
+		}
+		break
+	}
+
+	used := false
+	for _, stmt := range list[i+1:] {
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.Ident:
+				used = used || targets[n.Name]
+			case *ast.SelectorExpr, *ast.IndexExpr, *ast.StarExpr, *ast.CallExpr:
+				used = used || targets[types.ExprString(n.(ast.Expr))]
+			}
+			return !used
+		})
+		if used {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Require) assigns(assign *ast.AssignStmt, targets map[string]bool) bool {
+	for _, lhs := range assign.Lhs {
+		if id, ok := lhs.(*ast.Ident); ok && targets[id.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Require) spelling(rf *requireFile, form assertForm, name string) string {
+	switch form {
+	case formPackage:
+		return rf.assert + "." + name
+	case formAssertChain:
+		return rf.recv + ".Assert()." + name
+	default:
+		return rf.recv + "." + name
+	}
+}
@@ -0,0 +1,134 @@
+package codemod
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+)
+
+// sourceEdit replaces the source between two positions of a file.
+type sourceEdit struct {
+	start, end token.Pos
+	text       string
+}
+
+// edited returns the source of f with edits made, for codemods that write new code as text. The result is
+// parsed again so imports can adjust it to the code it now holds; any import nothing uses any more is then
+// removed, and the file is formatted.
+func (f *File) edited(fset *token.FileSet, edits []sourceEdit, imports func(*File, *token.FileSet)) ([]byte, error) {
+	src := f.splice(fset, 0, len(f.Src), edits)
+	newFset := token.NewFileSet()
+	parsed, err := parser.ParseFile(newFset, f.Path, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("convert %s: %w", f.Path, err)
+	}
+	converted := &File{Path: f.Path, AST: parsed, Test: f.Test}
+	imports(converted, newFset)
+	for _, spec := range append(parsed.Imports[:0:0], parsed.Imports...) {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		converted.dropImport(newFset, p)
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, newFset, converted.AST); err != nil {
+		return nil, fmt.Errorf("format %s: %w", f.Path, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// splice returns the source of f between two offsets with edits within them made. An insertion sorts before
+// an edit starting at the same position.
+func (f *File) splice(fset *token.FileSet, from, to int, edits []sourceEdit) []byte {
+	sort.Slice(edits, func(i, j int) bool {
+		if edits[i].start != edits[j].start {
+			return edits[i].start < edits[j].start
+		}
+		return edits[i].end < edits[j].end
+	})
+	var src bytes.Buffer
+	last := from
+	for _, e := range edits {
+		start, end := fset.Position(e.start).Offset, fset.Position(e.end).Offset
+		src.Write(f.Src[last:start])
+		src.WriteString(e.text)
+		last = end
+	}
+	src.Write(f.Src[last:to])
+	return src.Bytes()
+}
+
+// declared returns the package-level names of pkg.
+func (pkg *Package) declared() map[string]bool {
+	names := map[string]bool{}
+	for _, f := range pkg.Files {
+		for _, decl := range f.AST.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					names[decl.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						names[spec.Name.Name] = true
+					case *ast.ValueSpec:
+						for _, id := range spec.Names {
+							names[id.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// redeclared returns a name of names that stmts of f declare again, or "".
+func (f *File) redeclared(stmts []ast.Stmt, names map[string]bool) string {
+	found := ""
+	check := func(exprs ...ast.Expr) {
+		for _, e := range exprs {
+			if id, ok := e.(*ast.Ident); ok && found == "" && names[id.Name] {
+				found = id.Name
+			}
+		}
+	}
+	fields := func(list *ast.FieldList) {
+		if list == nil {
+			return
+		}
+		for _, field := range list.List {
+			for _, id := range field.Names {
+				check(id)
+			}
+		}
+	}
+	for _, stmt := range stmts {
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				if n.Tok == token.DEFINE {
+					check(n.Lhs...)
+				}
+			case *ast.RangeStmt:
+				if n.Tok == token.DEFINE {
+					check(n.Key, n.Value)
+				}
+			case *ast.ValueSpec:
+				for _, id := range n.Names {
+					check(id)
+				}
+			case *ast.FuncType:
+				fields(n.Params)
+				fields(n.Results)
+			}
+			return found == ""
+		})
+	}
+	return found
+}
@@ -0,0 +1,999 @@
+package codemod
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/gomod"
+)
+
+// Suite groups the flat test functions of a file that build the same system under test into a testify suite,
+// as Pattern 1 of the go-unit-tests skill has it.
+//
+// The sut of a test is the variable named sut, or else the first one its body assigns from a NewX
+// constructor other than a NewMock one; its dependencies are the := statements before it whose variables feed
+// the constructor call. Tests of a file whose sut comes from the same constructor and whose setup statements
+// read the same become methods of XTestSuite, named without a leading X_: the setup moves to SetupTest and its
+// variables to fields, a mock named after the constructor parameter it is passed as, like repoMock, and
+// TestXSuite runs the suite. In the bodies, assert.X(t, ...) becomes s.X(...), require.X(t, ...) becomes
+// s.Require().X(...), t.Run subtests become s.Run, and any other t becomes s.T(). Tests that build the sut
+// differently, call t.Parallel, or declare a field name again are reported and left alone, as are groups whose
+// field types cannot be told from the constructors.
+type Suite struct {
+	names *TestNames
+	// packages caches the parsed non-test files of the directories constructors are looked up in.
+	packages map[string][]*ast.File
+}
+
+// suiteFile is the import names of one test file.
+type suiteFile struct {
+	file    *File
+	testing string
+	assert  string
+	require string
+}
+
+// suiteTest is one flat test and the statements building its sut.
+type suiteTest struct {
+	fn *ast.FuncDecl
+	// constructor is the function the sut is built with, as spelled in the test, and name the X of its NewX.
+	constructor string
+	name        string
+	// setup is the statements building the dependencies, in order, and then the sut.
+	setup []*ast.AssignStmt
+	// key is the printed setup; tests whose keys differ build the sut differently.
+	key string
+	err error
+}
+
+// NewSuite returns the suite codemod.
+func NewSuite() *Suite {
+	return &Suite{names: NewTestNames(), packages: map[string][]*ast.File{}}
+}
+
+func (s *Suite) Rewrite(pkg *Package) ([]Change, error) {
+	var changes []Change
+	taken := pkg.declared()
+	for _, f := range pkg.Files {
+		if !f.Test {
+			continue
+		}
+		sf := s.open(f)
+		if sf == nil {
+			continue
+		}
+		var edits []sourceEdit
+		for _, group := range s.groups(pkg, sf) {
+			e, c := s.convert(pkg, sf, group, taken)
+			edits = append(edits, e...)
+			changes = append(changes, c...)
+		}
+		if len(edits) == 0 {
+			continue
+		}
+		src, err := f.edited(pkg.Fset, edits, func(converted *File, fset *token.FileSet) {
+			converted.addImport(fset, testifySuite)
+		})
+		if err != nil {
+			return nil, err
+		}
+		f.Replacement = src
+	}
+	return changes, nil
+}
+
+// open returns the import names of f, or nil when f does not import testing.
+func (s *Suite) open(f *File) *suiteFile {
+	name, ok := f.importName("testing")
+	if !ok {
+		return nil
+	}
+	sf := &suiteFile{file: f, testing: name}
+	sf.assert, _ = f.importName(testifyAssert)
+	sf.require, _ = f.importName(testifyRequire)
+	return sf
+}
+
+// groups returns the flat tests of a file that build a sut, grouped by constructor in file order.
+func (s *Suite) groups(pkg *Package, sf *suiteFile) [][]*suiteTest {
+	var order []string
+	byConstructor := map[string][]*suiteTest{}
+	for _, decl := range sf.file.AST.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || !s.flat(sf, fn) {
+			continue
+		}
+		t := s.test(pkg, fn)
+		if t == nil {
+			continue
+		}
+		if _, ok := byConstructor[t.constructor]; !ok {
+			order = append(order, t.constructor)
+		}
+		byConstructor[t.constructor] = append(byConstructor[t.constructor], t)
+	}
+	var groups [][]*suiteTest
+	for _, c := range order {
+		if len(byConstructor[c]) > 1 {
+			groups = append(groups, byConstructor[c])
+		}
+	}
+	return groups
+}
+
+// flat reports whether fn is a test function taking t *testing.T that does not run a suite itself.
+func (s *Suite) flat(sf *suiteFile, fn *ast.FuncDecl) bool {
+	rest, ok := strings.CutPrefix(fn.Name.Name, "Test")
+	if !ok || rest == "" || fn.Name.Name == "TestMain" || fn.Recv != nil || fn.Body == nil {
+		return false
+	}
+	if first, _ := utf8.DecodeRuneInString(rest); unicode.IsLower(first) {
+		return false
+	}
+	params := fn.Type.Params.List
+	if len(params) != 1 || len(params[0].Names) != 1 || params[0].Names[0].Name != "t" {
+		return false
+	}
+	return s.testingT(sf, params[0].Type) && !s.names.runsSuite(fn.Body)
+}
+
+// testingT reports whether e is *testing.T.
+func (s *Suite) testingT(sf *suiteFile, e ast.Expr) bool {
+	star, ok := e.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "T" {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	return ok && x.Name == sf.testing
+}
+
+// test returns the setup of fn, or nil when fn builds no sut. A test whose setup cannot be moved has err set.
+func (s *Suite) test(pkg *Package, fn *ast.FuncDecl) *suiteTest {
+	list := fn.Body.List
+	at := -1
+	for i, stmt := range list {
+		assign, ok := s.define(stmt)
+		if !ok {
+			continue
+		}
+		call, ok := assign.Rhs[0].(*ast.CallExpr)
+		if !ok || !s.constructor(call) {
+			continue
+		}
+		if assign.Lhs[0].(*ast.Ident).Name == "sut" {
+			at = i
+			break
+		}
+		if at < 0 {
+			at = i
+		}
+	}
+	if at < 0 {
+		return nil
+	}
+	sut := list[at].(*ast.AssignStmt)
+	fun := sut.Rhs[0].(*ast.CallExpr).Fun
+	t := &suiteTest{fn: fn, constructor: types.ExprString(fun)}
+	if sel, ok := fun.(*ast.SelectorExpr); ok {
+		fun = sel.Sel
+	}
+	t.name = strings.TrimPrefix(fun.(*ast.Ident).Name, "New")
+	needed := map[string]bool{}
+	s.reads(sut.Rhs[0], needed)
+	setup := []*ast.AssignStmt{sut}
+	for i := at - 1; i >= 0; i-- {
+		for _, name := range s.declares(list[i]) {
+			if !needed[name] {
+				continue
+			}
+			assign, ok := s.define(list[i])
+			if !ok {
+				t.err = fmt.Errorf("the sut needs %s, which is not declared alone with :=", name)
+				return t
+			}
+			delete(needed, name)
+			s.reads(assign.Rhs[0], needed)
+			setup = append([]*ast.AssignStmt{assign}, setup...)
+		}
+	}
+	t.setup = setup
+	var key bytes.Buffer
+	for _, assign := range setup {
+		if err := format.Node(&key, pkg.Fset, assign); err != nil {
+			t.err = err
+			return t
+		}
+		key.WriteByte('\n')
+	}
+	t.key = key.String()
+	return t
+}
+
+// define returns stmt when it declares one variable with :=.
+func (s *Suite) define(stmt ast.Stmt) (*ast.AssignStmt, bool) {
+	assign, ok := stmt.(*ast.AssignStmt)
+	if !ok || assign.Tok != token.DEFINE || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+		return nil, false
+	}
+	id, ok := assign.Lhs[0].(*ast.Ident)
+	return assign, ok && id.Name != "_"
+}
+
+// constructor reports whether call is to a NewX function that is not a NewMock one.
+func (s *Suite) constructor(call *ast.CallExpr) bool {
+	name := ""
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		name = fun.Name
+	case *ast.SelectorExpr:
+		if _, ok := fun.X.(*ast.Ident); ok {
+			name = fun.Sel.Name
+		}
+	}
+	rest, ok := strings.CutPrefix(name, "New")
+	if !ok || rest == "" || strings.HasPrefix(name, "NewMock") {
+		return false
+	}
+	first, _ := utf8.DecodeRuneInString(rest)
+	return unicode.IsUpper(first)
+}
+
+// declares returns the names stmt declares.
+func (s *Suite) declares(stmt ast.Stmt) []string {
+	var names []string
+	switch stmt := stmt.(type) {
+	case *ast.AssignStmt:
+		if stmt.Tok == token.DEFINE {
+			for _, e := range stmt.Lhs {
+				if id, ok := e.(*ast.Ident); ok {
+					names = append(names, id.Name)
+				}
+			}
+		}
+	case *ast.DeclStmt:
+		if gen, ok := stmt.Decl.(*ast.GenDecl); ok {
+			for _, spec := range gen.Specs {
+				if vs, ok := spec.(*ast.ValueSpec); ok {
+					for _, id := range vs.Names {
+						names = append(names, id.Name)
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// reads adds the identifiers node refers to, other than selected names and t, to names.
+func (s *Suite) reads(node ast.Node, names map[string]bool) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			s.reads(n.X, names)
+			return false
+		case *ast.KeyValueExpr:
+			if _, ok := n.Key.(*ast.Ident); ok {
+				s.reads(n.Value, names)
+				return false
+			}
+		case *ast.Ident:
+			if n.Name != "t" {
+				names[n.Name] = true
+			}
+		}
+		return true
+	})
+}
+
+// convert returns the edits turning a group of tests into a suite, and the changes. Tests that cannot join are
+// reported; the group is converted when at least two remain.
+func (s *Suite) convert(pkg *Package, sf *suiteFile, group []*suiteTest, taken map[string]bool) ([]sourceEdit,
+	[]Change) {
+	var changes []Change
+	skip := func(t *suiteTest, format string, args ...any) {
+		changes = append(changes, Change{
+			Pos:     pkg.Fset.Position(t.fn.Pos()),
+			Message: t.fn.Name.Name + ": " + fmt.Sprintf(format, args...),
+			Skipped: true,
+		})
+	}
+	var ref *suiteTest
+	var tests []*suiteTest
+	var fields map[string]string
+	for _, t := range group {
+		switch {
+		case t.err != nil:
+			skip(t, "%v", t.err)
+			continue
+		case ref == nil:
+			ref = t
+			fields = s.fields(pkg, sf, t)
+		case t.key != ref.key:
+			skip(t, "builds the sut differently from %s", ref.fn.Name.Name)
+			continue
+		}
+		if reason := s.unsupported(sf, t, fields); reason != "" {
+			skip(t, "%s", reason)
+			continue
+		}
+		tests = append(tests, t)
+	}
+	if len(tests) < 2 {
+		for _, t := range tests {
+			skip(t, "no other test builds the sut with %s the same way", t.constructor)
+		}
+		return nil, changes
+	}
+
+	typeName, runner := ref.name+"TestSuite", "Test"+ref.name+"Suite"
+	for _, n := range []string{typeName, runner} {
+		if taken[n] {
+			skip(tests[0], "%s is already declared; left the tests building the sut with %s alone", n,
+				ref.constructor)
+			return nil, changes
+		}
+	}
+	var typed []string
+	for _, assign := range ref.setup {
+		typ, err := s.typeOf(pkg, sf, assign.Rhs[0])
+		if err != nil {
+			skip(tests[0], "cannot tell the type of %s: %v; left the tests building the sut with %s alone",
+				assign.Lhs[0].(*ast.Ident).Name, err, ref.constructor)
+			return nil, changes
+		}
+		typed = append(typed, typ)
+	}
+	taken[typeName], taken[runner] = true, true
+
+	first := tests[0].fn
+	at := first.Pos()
+	if first.Doc != nil {
+		at = first.Doc.Pos()
+	}
+	edits := []sourceEdit{{start: at, end: at, text: s.header(pkg, sf, ref, typed, typeName, runner, fields)}}
+	var names []string
+	methods := s.methodNames(tests, ref.name)
+	for i, t := range tests {
+		edits = append(edits, s.edits(pkg, sf, t, typeName, methods[i], fields)...)
+		names = append(names, t.fn.Name.Name)
+	}
+	changes = append(changes, Change{
+		Pos: pkg.Fset.Position(first.Pos()),
+		Message: fmt.Sprintf("added %s building the sut with %s in SetupTest for %s", typeName, ref.constructor,
+			strings.Join(names, ", ")),
+	})
+	for i, t := range tests {
+		msg := fmt.Sprintf("converted %s to a method of %s", t.fn.Name.Name, typeName)
+		if methods[i] != t.fn.Name.Name {
+			msg = fmt.Sprintf("converted %s to the method %s of %s", t.fn.Name.Name, methods[i], typeName)
+		}
+		changes = append(changes, Change{Pos: pkg.Fset.Position(t.fn.Pos()), Message: msg})
+	}
+	return edits, changes
+}
+
+// methodNames returns the suite method name of each test: its name without the Type_ the suite already
+// names, so TestUserService_Create_ValidInput_Succeeds becomes TestCreate_ValidInput_Succeeds. Tests whose
+// shortened names would clash keep theirs.
+func (s *Suite) methodNames(tests []*suiteTest, typ string) []string {
+	names := make([]string, len(tests))
+	count := map[string]int{}
+	for i, t := range tests {
+		names[i] = t.fn.Name.Name
+		if rest, ok := strings.CutPrefix(t.fn.Name.Name, "Test"+typ+"_"); ok && rest != "" &&
+			unicode.IsUpper([]rune(rest)[0]) {
+			names[i] = "Test" + rest
+		}
+		count[names[i]]++
+	}
+	for i, t := range tests {
+		if count[names[i]] > 1 {
+			names[i] = t.fn.Name.Name
+		}
+	}
+	return names
+}
+
+// fields returns the suite field name of each variable the setup of t declares. A mock is named after the
+// constructor parameter it is passed as, with a Mock suffix, as scaffold names it: repoMock for a mock passed
+// as repo. Any other variable, or a mock whose name would be taken, keeps its name.
+func (s *Suite) fields(pkg *Package, sf *suiteFile, t *suiteTest) map[string]string {
+	fields := map[string]string{}
+	taken := map[string]bool{}
+	for _, assign := range t.setup {
+		local := assign.Lhs[0].(*ast.Ident).Name
+		fields[local], taken[local] = local, true
+	}
+	sut := t.setup[len(t.setup)-1].Rhs[0].(*ast.CallExpr)
+	params := s.params(pkg, sf, sut)
+	for _, assign := range t.setup[:len(t.setup)-1] {
+		call, ok := assign.Rhs[0].(*ast.CallExpr)
+		if !ok || !strings.HasPrefix(s.names.callee(call.Fun), "NewMock") {
+			continue
+		}
+		local := assign.Lhs[0].(*ast.Ident).Name
+		dep := local
+		for i, arg := range sut.Args {
+			if id, ok := arg.(*ast.Ident); ok && id.Name == local && i < len(params) && params[i] != "_" {
+				dep = params[i]
+			}
+		}
+		name := s.mockField(dep)
+		if name != local && !taken[name] {
+			fields[local], taken[name] = name, true
+		}
+	}
+	return fields
+}
+
+// mockField returns the field name of the mock of dep: dep with a Mock suffix, and no mock prefix.
+func (s *Suite) mockField(dep string) string {
+	if strings.HasSuffix(dep, "Mock") {
+		return dep
+	}
+	if rest, ok := strings.CutPrefix(dep, "mock"); ok && rest != "" {
+		first, size := utf8.DecodeRuneInString(rest)
+		if unicode.IsUpper(first) {
+			dep = string(unicode.ToLower(first)) + rest[size:]
+		}
+	}
+	return dep + "Mock"
+}
+
+// unsupported returns why the body of t cannot become a suite method, or "".
+func (s *Suite) unsupported(sf *suiteFile, t *suiteTest, fields map[string]string) string {
+	kept := s.kept(t)
+	locals := map[string]bool{}
+	for local := range fields {
+		locals[local] = true
+	}
+	if name := sf.file.redeclared(kept, locals); name != "" {
+		return fmt.Sprintf("%s is declared again, and would be the suite field", name)
+	}
+	reason := ""
+	var visit func(ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Ident:
+			if n.Name == "s" {
+				reason = "the name s is taken by the suite receiver"
+			}
+		case *ast.CallExpr:
+			if sel, ok := n.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Parallel" {
+				if x, ok := sel.X.(*ast.Ident); ok && x.Name == "t" {
+					reason = "calls t.Parallel, which suite tests do not support"
+				}
+			}
+			if lit := s.subtest(sf, n); lit != nil {
+				ast.Inspect(n.Args[0], visit)
+				ast.Inspect(lit.Body, visit)
+				return false
+			}
+		case *ast.FuncLit:
+			for _, field := range n.Type.Params.List {
+				if s.testingT(sf, field.Type) {
+					reason = "a function literal other than a t.Run subtest takes *testing.T"
+				}
+			}
+		}
+		return reason == ""
+	}
+	for _, stmt := range kept {
+		ast.Inspect(stmt, visit)
+	}
+	return reason
+}
+
+// kept returns the statements of t that stay in the test.
+func (s *Suite) kept(t *suiteTest) []ast.Stmt {
+	setup := map[ast.Stmt]bool{}
+	for _, assign := range t.setup {
+		setup[assign] = true
+	}
+	var kept []ast.Stmt
+	for _, stmt := range t.fn.Body.List {
+		if !setup[stmt] {
+			kept = append(kept, stmt)
+		}
+	}
+	return kept
+}
+
+// subtest returns the function literal of t.Run(name, func(t *testing.T) {...}), or nil.
+func (s *Suite) subtest(sf *suiteFile, call *ast.CallExpr) *ast.FuncLit {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Run" || len(call.Args) != 2 {
+		return nil
+	}
+	if x, ok := sel.X.(*ast.Ident); !ok || x.Name != "t" {
+		return nil
+	}
+	lit, ok := call.Args[1].(*ast.FuncLit)
+	if !ok || lit.Type.Results != nil || len(lit.Type.Params.List) != 1 {
+		return nil
+	}
+	param := lit.Type.Params.List[0]
+	if len(param.Names) != 1 || param.Names[0].Name != "t" || !s.testingT(sf, param.Type) {
+		return nil
+	}
+	return lit
+}
+
+// header returns the suite type, its SetupTest building the sut as ref does, and the function running it.
+func (s *Suite) header(pkg *Package, sf *suiteFile, ref *suiteTest, typed []string, typeName, runner string,
+	fields map[string]string) string {
+	suiteName, ok := sf.file.importName(testifySuite)
+	if !ok {
+		suiteName = path.Base(testifySuite)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "type %s struct {\n\t%s.Suite\n", typeName, suiteName)
+	last := len(ref.setup) - 1
+	// The sut comes first, as in the skill's examples.
+	fmt.Fprintf(&b, "\t%s %s\n", fields[ref.setup[last].Lhs[0].(*ast.Ident).Name], typed[last])
+	for i, assign := range ref.setup[:last] {
+		fmt.Fprintf(&b, "\t%s %s\n", fields[assign.Lhs[0].(*ast.Ident).Name], typed[i])
+	}
+	b.WriteString("}\n\n")
+	fmt.Fprintf(&b, "func (s *%s) SetupTest() {\n", typeName)
+	for i, assign := range ref.setup {
+		if i == last && i > 0 {
+			b.WriteString("\n")
+		}
+		edits := []sourceEdit{
+			{start: assign.Lhs[0].Pos(), end: assign.Lhs[0].End(), text: "s." + fields[assign.Lhs[0].(*ast.Ident).Name]},
+			{start: assign.TokPos, end: assign.TokPos + 2, text: "="},
+		}
+		s.rewrite(sf, assign.Rhs[0], fields, &edits)
+		b.WriteString("\t")
+		b.Write(sf.file.splice(pkg.Fset, s.offset(sf.file, assign.Pos()), s.offset(sf.file, assign.End()), edits))
+		b.WriteString("\n")
+	}
+	b.WriteString("}\n\n")
+	fmt.Fprintf(&b, "func %s(t *%s.T) {\n\t%s.Run(t, new(%s))\n}\n\n", runner, sf.testing, suiteName, typeName)
+	return b.String()
+}
+
+// edits returns the edits turning t into a method of the suite: the signature, the setup removed, and the
+// body rewritten to the fields and the suite's assertions.
+func (s *Suite) edits(pkg *Package, sf *suiteFile, t *suiteTest, typeName, method string,
+	fields map[string]string) []sourceEdit {
+	edits := []sourceEdit{{
+		start: t.fn.Pos(),
+		end:   t.fn.Body.Lbrace,
+		text:  fmt.Sprintf("func (s *%s) %s() ", typeName, method),
+	}}
+	for _, stmt := range s.kept(t) {
+		s.rewrite(sf, stmt, fields, &edits)
+	}
+	return append(edits, s.removals(pkg, sf, t)...)
+}
+
+// rewrite adds the edits that make node refer to the suite: fields through s, assertions on the suite, s.Run
+// for t.Run subtests, and s.T() for any other t.
+func (s *Suite) rewrite(sf *suiteFile, node ast.Node, fields map[string]string, edits *[]sourceEdit) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			s.rewrite(sf, n.X, fields, edits)
+			return false
+		case *ast.KeyValueExpr:
+			if _, ok := n.Key.(*ast.Ident); ok {
+				s.rewrite(sf, n.Value, fields, edits)
+				return false
+			}
+		case *ast.CallExpr:
+			if lit := s.subtest(sf, n); lit != nil {
+				x := n.Fun.(*ast.SelectorExpr).X
+				*edits = append(*edits,
+					sourceEdit{start: x.Pos(), end: x.End(), text: "s"},
+					sourceEdit{start: lit.Type.Pos(), end: lit.Type.End(), text: "func()"})
+				s.rewrite(sf, n.Args[0], fields, edits)
+				s.rewrite(sf, lit.Body, fields, edits)
+				return false
+			}
+			if method, ok := s.assertion(sf, n); ok {
+				*edits = append(*edits, sourceEdit{start: n.Fun.Pos(), end: s.afterT(sf, n), text: method + "("})
+				for _, arg := range n.Args[1:] {
+					s.rewrite(sf, arg, fields, edits)
+				}
+				return false
+			}
+		case *ast.Ident:
+			switch {
+			case n.Name == "t":
+				*edits = append(*edits, sourceEdit{start: n.Pos(), end: n.End(), text: "s.T()"})
+			case fields[n.Name] != "":
+				*edits = append(*edits, sourceEdit{start: n.Pos(), end: n.End(), text: "s." + fields[n.Name]})
+			}
+		}
+		return true
+	})
+}
+
+// assertion returns the suite spelling of assert.X(t, ...) or require.X(t, ...).
+func (s *Suite) assertion(sf *suiteFile, call *ast.CallExpr) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) == 0 || !assertions[strings.TrimSuffix(sel.Sel.Name, "f")] {
+		return "", false
+	}
+	if t, ok := call.Args[0].(*ast.Ident); !ok || t.Name != "t" {
+		return "", false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	switch {
+	case !ok:
+		return "", false
+	case sf.assert != "" && x.Name == sf.assert:
+		return "s." + sel.Sel.Name, true
+	case sf.require != "" && x.Name == sf.require:
+		return "s.Require()." + sel.Sel.Name, true
+	}
+	return "", false
+}
+
+// afterT returns the position after the t argument of call and the comma following it.
+func (s *Suite) afterT(sf *suiteFile, call *ast.CallExpr) token.Pos {
+	end := call.Args[0].End()
+	if len(call.Args) == 1 {
+		return end
+	}
+	for i := 0; ; i++ {
+		switch sf.file.Src[s.offset(sf.file, end)+i] {
+		case ' ', '\t':
+			continue
+		case ',':
+			return end + token.Pos(i+1)
+		}
+		return end
+	}
+}
+
+// offset returns the offset of pos in the source of f.
+func (s *Suite) offset(f *File, pos token.Pos) int {
+	return int(pos) - int(f.AST.FileStart)
+}
+
+// removals returns the edits removing the setup of t, with the comments above each statement, an
+// "// Arrange" comment left with no statement under it, and the blank line a removal would leave at the start
+// of the body or next to another.
+func (s *Suite) removals(pkg *Package, sf *suiteFile, t *suiteTest) []sourceEdit {
+	src := sf.file.Src
+	body := t.fn.Body
+	var comments []*ast.CommentGroup
+	for _, cg := range sf.file.AST.Comments {
+		if cg.Pos() > body.Lbrace && cg.End() < body.Rbrace {
+			comments = append(comments, cg)
+		}
+	}
+	line := func(pos token.Pos) int { return pkg.Fset.Position(pos).Line }
+	type span struct{ start, end int }
+	var spans []span
+	for _, assign := range t.setup {
+		start, end, _ := s.lines(sf, assign.Pos(), assign.End())
+		spans = append(spans, span{start, end})
+		above := line(assign.Pos())
+		for i := len(comments) - 1; i >= 0; i-- {
+			cg := comments[i]
+			if line(cg.End()) != above-1 || aaaComments[strings.TrimSpace(cg.List[0].Text)] {
+				continue
+			}
+			if start, end, whole := s.lines(sf, cg.Pos(), cg.End()); whole {
+				spans = append(spans, span{start, end})
+				above = line(cg.Pos())
+			}
+		}
+	}
+	kept := s.kept(t)
+	for i, cg := range comments {
+		if strings.TrimSpace(cg.List[0].Text) != "// Arrange" || len(cg.List) != 1 {
+			continue
+		}
+		next := body.Rbrace
+		for _, later := range comments[i+1:] {
+			if aaaComments[strings.TrimSpace(later.List[0].Text)] {
+				next = later.Pos()
+				break
+			}
+		}
+		empty := true
+		for _, stmt := range kept {
+			if stmt.Pos() > cg.End() && stmt.Pos() < next {
+				empty = false
+			}
+		}
+		if empty {
+			start, end, _ := s.lines(sf, cg.Pos(), cg.End())
+			spans = append(spans, span{start, end})
+		}
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var merged []span
+	for _, sp := range spans {
+		if n := len(merged); n > 0 && sp.start <= merged[n-1].end {
+			merged[n-1].end = max(merged[n-1].end, sp.end)
+			continue
+		}
+		merged = append(merged, sp)
+	}
+	var edits []sourceEdit
+	base := sf.file.AST.FileStart
+	for _, sp := range merged {
+		before := strings.TrimRight(string(src[:sp.start]), " \t")
+		after := strings.TrimLeft(string(src[sp.end:]), " \t")
+		atOpen := strings.HasSuffix(before, "{\n")
+		prevBlank := strings.HasSuffix(before, "\n\n")
+		switch {
+		case (atOpen || prevBlank) && strings.HasPrefix(after, "\n"):
+			sp.end = len(src) - len(after) + 1
+		case prevBlank && strings.HasPrefix(after, "}"):
+			sp.start = len(before) - 1
+		}
+		edits = append(edits, sourceEdit{start: base + token.Pos(sp.start), end: base + token.Pos(sp.end)})
+	}
+	return edits
+}
+
+// lines returns the offsets of the whole lines from pos to end, and false when code other than a trailing
+// comment shares them, with the offsets of pos and end.
+func (s *Suite) lines(sf *suiteFile, pos, end token.Pos) (int, int, bool) {
+	src := sf.file.Src
+	start, stop := s.offset(sf.file, pos), s.offset(sf.file, end)
+	lineStart := start
+	for lineStart > 0 && (src[lineStart-1] == ' ' || src[lineStart-1] == '\t') {
+		lineStart--
+	}
+	if lineStart > 0 && src[lineStart-1] != '\n' {
+		return start, stop, false
+	}
+	lineEnd := stop
+	for lineEnd < len(src) && (src[lineEnd] == ' ' || src[lineEnd] == '\t') {
+		lineEnd++
+	}
+	if bytes.HasPrefix(src[lineEnd:], []byte("//")) {
+		lineEnd += bytes.IndexByte(src[lineEnd:], '\n')
+	}
+	if lineEnd < len(src) && src[lineEnd] != '\n' {
+		return start, stop, false
+	}
+	return lineStart, min(lineEnd+1, len(src)), true
+}
+
+// typeOf returns the type of the field e is assigned to, spelled as the test file refers to it: that of a
+// literal, or the result of the constructor called.
+func (s *Suite) typeOf(pkg *Package, sf *suiteFile, e ast.Expr) (string, error) {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		return map[token.Token]string{
+			token.INT: "int", token.FLOAT: "float64", token.IMAG: "complex128", token.CHAR: "rune",
+			token.STRING: "string",
+		}[e.Kind], nil
+	case *ast.CompositeLit:
+		if e.Type != nil {
+			return types.ExprString(e.Type), nil
+		}
+	case *ast.UnaryExpr:
+		if lit, ok := e.X.(*ast.CompositeLit); ok && e.Op == token.AND && lit.Type != nil {
+			return "*" + types.ExprString(lit.Type), nil
+		}
+	case *ast.CallExpr:
+		return s.result(pkg, sf, e)
+	}
+	return "", fmt.Errorf("%s is neither a literal nor a function call", types.ExprString(e))
+}
+
+// result returns the type call returns, looked up in the declaration of the function called.
+func (s *Suite) result(pkg *Package, sf *suiteFile, call *ast.CallExpr) (string, error) {
+	fn, f, qualifier, err := s.declaration(pkg, sf, call)
+	if err != nil {
+		return "", err
+	}
+	results := fn.Type.Results
+	if fn.Type.TypeParams != nil || results == nil || len(results.List) != 1 || len(results.List[0].Names) > 1 {
+		return "", fmt.Errorf("%s does not return one value of a fixed type", types.ExprString(call.Fun))
+	}
+	return s.spell(sf, f, results.List[0].Type, qualifier)
+}
+
+// params returns the parameter names of the function call calls, or nil when its declaration is not found.
+func (s *Suite) params(pkg *Package, sf *suiteFile, call *ast.CallExpr) []string {
+	fn, _, _, err := s.declaration(pkg, sf, call)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, field := range fn.Type.Params.List {
+		for _, id := range field.Names {
+			names = append(names, id.Name)
+		}
+	}
+	return names
+}
+
+// declaration returns the declaration of the package function call calls, the file declaring it, and the test
+// file's name for its package, or "" for the test file's own package.
+func (s *Suite) declaration(pkg *Package, sf *suiteFile, call *ast.CallExpr) (*ast.FuncDecl, *ast.File, string,
+	error) {
+	var files []*ast.File
+	qualifier, name := "", ""
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		name = fun.Name
+		for _, f := range pkg.Files {
+			if f.AST.Name.Name == sf.file.AST.Name.Name {
+				files = append(files, f.AST)
+			}
+		}
+	case *ast.SelectorExpr:
+		x, ok := fun.X.(*ast.Ident)
+		if !ok {
+			return nil, nil, "", fmt.Errorf("%s is not a package function", types.ExprString(fun))
+		}
+		importPath, ok := s.importPath(sf.file.AST, x.Name)
+		if !ok {
+			return nil, nil, "", fmt.Errorf("%s is not an imported package", x.Name)
+		}
+		var err error
+		if files, err = s.load(pkg, importPath); err != nil {
+			return nil, nil, "", err
+		}
+		qualifier, name = x.Name, fun.Sel.Name
+	default:
+		return nil, nil, "", fmt.Errorf("%s is not a package function", types.ExprString(call.Fun))
+	}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == name {
+				return fn, f, qualifier, nil
+			}
+		}
+	}
+	return nil, nil, "", fmt.Errorf("%s is not declared", types.ExprString(call.Fun))
+}
+
+// spell returns typ, declared in file decl, as the test file refers to it; qualifier is the test file's name
+// for the package of decl, or "" for its own package.
+func (s *Suite) spell(sf *suiteFile, decl *ast.File, typ ast.Expr, qualifier string) (string, error) {
+	switch t := typ.(type) {
+	case *ast.Ident:
+		if qualifier == "" || types.Universe.Lookup(t.Name) != nil {
+			return t.Name, nil
+		}
+		return qualifier + "." + t.Name, nil
+	case *ast.StarExpr:
+		x, err := s.spell(sf, decl, t.X, qualifier)
+		return "*" + x, err
+	case *ast.ArrayType:
+		if t.Len == nil {
+			elt, err := s.spell(sf, decl, t.Elt, qualifier)
+			return "[]" + elt, err
+		}
+	case *ast.MapType:
+		key, err := s.spell(sf, decl, t.Key, qualifier)
+		if err != nil {
+			return "", err
+		}
+		value, err := s.spell(sf, decl, t.Value, qualifier)
+		return "map[" + key + "]" + value, err
+	case *ast.SelectorExpr:
+		x, ok := t.X.(*ast.Ident)
+		if !ok {
+			break
+		}
+		importPath, ok := s.importPath(decl, x.Name)
+		if !ok {
+			break
+		}
+		name, ok := sf.file.importName(importPath)
+		if !ok {
+			return "", fmt.Errorf("the test file does not import %s", importPath)
+		}
+		return name + "." + t.Sel.Name, nil
+	case *ast.IndexExpr:
+		return s.spellIndex(sf, decl, t.X, []ast.Expr{t.Index}, qualifier)
+	case *ast.IndexListExpr:
+		return s.spellIndex(sf, decl, t.X, t.Indices, qualifier)
+	}
+	return "", fmt.Errorf("cannot spell %s", types.ExprString(typ))
+}
+
+func (s *Suite) spellIndex(sf *suiteFile, decl *ast.File, x ast.Expr, indices []ast.Expr,
+	qualifier string) (string, error) {
+	base, err := s.spell(sf, decl, x, qualifier)
+	if err != nil {
+		return "", err
+	}
+	args := make([]string, len(indices))
+	for i, index := range indices {
+		if args[i], err = s.spell(sf, decl, index, qualifier); err != nil {
+			return "", err
+		}
+	}
+	return base + "[" + strings.Join(args, ", ") + "]", nil
+}
+
+// importPath returns the path file imports under name.
+func (s *Suite) importPath(file *ast.File, name string) (string, bool) {
+	f := &File{AST: file}
+	for _, spec := range file.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		if n, _ := f.importName(p); n == name {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// load returns the non-test files of the package at importPath, which must belong to the module of pkg.
+func (s *Suite) load(pkg *Package, importPath string) ([]*ast.File, error) {
+	module, err := s.module(pkg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	rel, ok := module.Rel(importPath)
+	if !ok {
+		return nil, fmt.Errorf("%s is outside the module", importPath)
+	}
+	dir := module.Abs(rel)
+	if files, ok := s.packages[dir]; ok {
+		return files, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", importPath, err)
+	}
+	var files []*ast.File
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", filepath.Join(dir, name), err)
+		}
+		files = append(files, f)
+	}
+	s.packages[dir] = files
+	return files, nil
+}
+
+// module returns the module containing dir, found by walking up to its go.mod.
+func (s *Suite) module(dir string) (*gomod.Module, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", dir, err)
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(abs, "go.mod")); err == nil {
+			return gomod.NewModule(abs)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("find go.mod: %w", err)
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return nil, fmt.Errorf("%s is not inside a module", dir)
+		}
+		abs = parent
+	}
+}
@@ -0,0 +1,28 @@
+package calc
+
+import "errors"
+
+var ErrNegative = errors.New("negative number")
+
+type Calculator struct {
+	base int
+}
+
+func NewCalculator(base int) *Calculator {
+	return &Calculator{base: base}
+}
+
+func (c *Calculator) Add(n int) (int, error) {
+	if n < 0 {
+		return 0, ErrNegative
+	}
+	return c.base + n, nil
+}
+
+func (c *Calculator) Digits(n int) []int {
+	var digits []int
+	for ; n > 0; n /= 10 {
+		digits = append(digits, n%10)
+	}
+	return digits
+}
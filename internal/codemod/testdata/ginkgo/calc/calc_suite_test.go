@@ -0,0 +1,13 @@
+package calc_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCalc(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Calc Suite")
+}
@@ -0,0 +1,76 @@
+package calc_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"example.com/calc"
+)
+
+var _ = Describe("Calc", func() {
+	It("adds the base", func() {
+		c := calc.NewCalculator(2)
+
+		got, err := c.Add(3)
+
+		Expect(err).To(BeNil())
+		Expect(got).To(Equal(5))
+	})
+
+	It("returns ErrNegative when add number is negative", func() {
+		c := calc.NewCalculator(2)
+
+		_, err := c.Add(-1)
+
+		Expect(err).To(MatchError(calc.ErrNegative))
+	})
+
+	It("splits the digits", func() {
+		c := calc.NewCalculator(0)
+		want := []int{3, 2, 1}
+
+		Expect(c.Digits(123)).To(ConsistOf(1, 2, 3))
+		Expect(c.Digits(123)).To(ConsistOf(want))
+	})
+
+	DescribeTable("add table",
+		func(n, want int) {
+			c := calc.NewCalculator(1)
+
+			got, err := c.Add(n)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got).To(Equal(want))
+		},
+		Entry("zero", 0, 1),
+		Entry("one", 1, 2),
+	)
+})
+
+var _ = Describe("Calc with a base", func() {
+	var c *calc.Calculator
+
+	BeforeEach(func() {
+		c = calc.NewCalculator(5)
+	})
+
+	It("adds", func() {
+		got, err := c.Add(1)
+
+		Expect(err).To(BeNil())
+		Expect(got).To(Equal(6))
+	})
+
+	It("fails", func() {
+		_, err := c.Add(-1)
+
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("builds the calculator", func() {
+		want := 5
+
+		Expect(c).NotTo(BeNil())
+		Expect(want).To(Equal(5))
+	})
+})
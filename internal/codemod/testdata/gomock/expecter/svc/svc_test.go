@@ -0,0 +1,25 @@
+package svc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"example.com/app/svc"
+	"example.com/app/svc/svcmock"
+)
+
+func TestService_Get_ValidInput_ReturnsName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := svcmock.NewMockRepository(ctrl)
+	repo.EXPECT().Find(gomock.Any()).Return("a", nil)
+	repo.EXPECT().Find(2).DoAndReturn(func(id int) (string, error) { return "b", nil }).Times(2)
+	repo.EXPECT().Save(gomock.Eq("a")).Do(func(name string) {}).Return(nil).AnyTimes()
+	sut := svc.NewService(repo)
+
+	got, err := sut.Get(1)
+
+	require.NoError(t, err)
+	require.Equal(t, "a", got)
+}
@@ -0,0 +1,62 @@
+package svc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+
+	"example.com/app/svc"
+	"example.com/app/svc/svcmock"
+)
+
+//go:generate mockgen -source=../svc.go -destination=svcmock/mock.go -package=svcmock
+
+func TestService_Get_ValidInput_ReturnsName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := svcmock.NewMockRepository(ctrl)
+	repo.EXPECT().Find(gomock.Eq(1)).Return("a", nil)
+	repo.EXPECT().Find(gomock.Any()).Return("b", nil).Times(2)
+	repo.EXPECT().Find(5).Return("", nil).Times(1)
+	repo.EXPECT().Find(3).Return("c", nil).Times(3)
+	repo.EXPECT().Save(gomock.Nil()).Return(nil).AnyTimes()
+	repo.EXPECT().Find(gomock.Cond(func(x any) bool { return x.(int) > 9 })).
+		DoAndReturn(func(id int) (string, error) { return "d", nil })
+	sut := svc.NewService(repo)
+
+	got, err := sut.Get(1)
+
+	require.NoError(t, err)
+	require.Equal(t, "a", got)
+}
+
+type ServiceTestSuite struct {
+	suite.Suite
+	ctrl *gomock.Controller
+	repo *svcmock.MockRepository
+	sut  *svc.Service
+}
+
+func (s *ServiceTestSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.repo = svcmock.NewMockRepository(s.ctrl)
+	s.sut = svc.NewService(s.repo)
+}
+
+func (s *ServiceTestSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func TestServiceSuite(t *testing.T) {
+	suite.Run(t, new(ServiceTestSuite))
+}
+
+func (s *ServiceTestSuite) TestSave_ValidInput_Succeeds() {
+	s.repo.EXPECT().Save(gomock.AssignableToTypeOf("")).Return(nil)
+
+	err := s.sut.Save("a")
+
+	s.Require().NoError(err)
+}
@@ -0,0 +1,66 @@
+package user_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"example.com/app/user"
+)
+
+func TestList_ValidInput_ReturnsUsers(t *testing.T) {
+	// Arrange
+	sut := user.NewService()
+
+	// Act
+	got, err := sut.List()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotEmpty(t, got)
+	assert.Equal(t, "ada", got[0].Name)
+	assert.Len(t, got, 3)
+}
+
+func TestList_Concurrently_ReturnsUsers(t *testing.T) {
+	// Arrange
+	sut := user.NewService()
+	var wg sync.WaitGroup
+
+	// Act
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := sut.List()
+		assert.NoError(t, err)
+	}()
+	wg.Wait()
+
+	// Assert
+	assert.True(t, sut.Listed())
+}
+
+type UserServiceTestSuite struct {
+	suite.Suite
+	sut *user.Service
+}
+
+func (s *UserServiceTestSuite) SetupTest() {
+	s.sut = user.NewService()
+}
+
+func TestUserServiceSuite(t *testing.T) {
+	suite.Run(t, new(UserServiceTestSuite))
+}
+
+func (s *UserServiceTestSuite) TestFind_ValidInput_ReturnsUser() {
+	// Act
+	got, err := s.sut.Find(1)
+
+	// Assert
+	s.Assert().NoError(err)
+	s.NotNil(got)
+	s.Assert().Equal("ada", got.Name)
+}
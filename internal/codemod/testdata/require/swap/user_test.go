@@ -0,0 +1,34 @@
+package user_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/app/user"
+)
+
+func TestFind_ValidInput_ReturnsUser(t *testing.T) {
+	// Arrange
+	sut := user.NewService()
+
+	// Act
+	got, err := sut.Find(1)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, got)
+	assert.Len(t, got.Roles, 2)
+	_ = got.Roles[1]
+}
+
+func TestFind_UnknownID_ReturnsNotFoundError(t *testing.T) {
+	// Arrange
+	sut := user.NewService()
+
+	// Act
+	_, err := sut.Find(2)
+
+	// Assert
+	assert.ErrorIs(t, err, user.ErrNotFound)
+}
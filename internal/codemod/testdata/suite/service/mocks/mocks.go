@@ -0,0 +1,15 @@
+package mocks
+
+import "testing"
+
+type MockUserRepository struct {
+	err error
+}
+
+func NewMockUserRepository(t *testing.T) *MockUserRepository {
+	return &MockUserRepository{}
+}
+
+func (m *MockUserRepository) Save(name string) error {
+	return m.err
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrEmptyName = errors.New("empty name")
+
+type UserRepository interface {
+	Save(name string) error
+}
+
+type UserService struct {
+	repo   UserRepository
+	prefix string
+}
+
+func NewUserService(userRepo UserRepository, prefix string) *UserService {
+	return &UserService{repo: userRepo, prefix: prefix}
+}
+
+func (s *UserService) Create(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return ErrEmptyName
+	}
+	return s.repo.Save(s.prefix + name)
+}
@@ -0,0 +1,51 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/service"
+	"example.com/service/mocks"
+)
+
+func TestUserService_Create_ValidInput_Succeeds(t *testing.T) {
+	// Arrange
+	repo := mocks.NewMockUserRepository(t)
+	prefix := "user-"
+	sut := service.NewUserService(repo, prefix)
+
+	// Act
+	err := sut.Create("ann")
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotNil(t, repo)
+}
+
+func TestUserService_Create_EmptyName_ReturnsEmptyNameError(t *testing.T) {
+	// Arrange
+	repo := mocks.NewMockUserRepository(t)
+	prefix := "user-"
+	sut := service.NewUserService(repo, prefix)
+
+	// Act
+	err := sut.Create(" ")
+
+	// Assert
+	require.ErrorIs(t, err, service.ErrEmptyName)
+}
+
+func TestCreate_EmptyName_ReturnsEmptyNameError(t *testing.T) {
+	// Arrange
+	repo := mocks.NewMockUserRepository(t)
+	prefix := "user-"
+	sut := service.NewUserService(repo, prefix)
+
+	// Act
+	err := sut.Create("")
+
+	// Assert
+	require.ErrorIs(t, err, service.ErrEmptyName)
+}
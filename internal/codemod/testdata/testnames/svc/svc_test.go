@@ -0,0 +1,85 @@
+package svc_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	req "github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"example.com/app/svc"
+	"example.com/app/test/mocks"
+)
+
+func TestFetch(t *testing.T) {
+	// Arrange
+	s := svc.NewService(nil)
+
+	// Act
+	got, ok := s.Get(1)
+
+	// Assert
+	req.True(t, ok)
+	req.Equal(t, "a", got)
+}
+
+func TestSave(t *testing.T) {
+	// Arrange
+	repo := mocks.NewMockRepository(t)
+	repo.On("Save", mock.Anything).Run(func(args mock.Arguments) {
+		assert.Equal(t, "a", args.Get(0))
+	}).Return(true)
+	sut := svc.NewService(repo)
+
+	// Act
+	ok := sut.Save("a")
+
+	// Assert
+	req.True(t, ok)
+}
+
+func TestService_Delete(t *testing.T) {
+	tests := []struct {
+		name string
+		id   int
+	}{
+		{name: "first", id: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sut := svc.NewService(nil)
+
+			err := sut.Delete(tt.id)
+
+			req.ErrorIs(t, err, svc.ErrNotFound)
+		})
+	}
+}
+
+type ServiceTestSuite struct {
+	suite.Suite
+	repo *mocks.MockRepository
+	sut  *svc.Service
+}
+
+func (ts *ServiceTestSuite) SetupTest() {
+	ts.repo = mocks.NewMockRepository(ts.T())
+	ts.sut = svc.NewService(ts.repo)
+}
+
+func TestServiceSuite(t *testing.T) {
+	suite.Run(t, new(ServiceTestSuite))
+}
+
+func (ts *ServiceTestSuite) TestUpdate() {
+	// Arrange
+	ts.repo.On("Update", 1).Return(errors.New("db down"))
+
+	// Act
+	err := ts.sut.Update(1)
+
+	// Assert
+	ts.Error(err)
+}
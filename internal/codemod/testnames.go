@@ -0,0 +1,526 @@
+package codemod
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// conformingName matches TestMethod_Scenario_Expectation and TestType_Method_Scenario_Expectation.
+var conformingName = regexp.MustCompile(`^Test[A-Z][A-Za-z0-9]*(_[A-Z][A-Za-z0-9]*){2,3}$`)
+
+// expectationPrefixes start the last part of an old name that already states an expectation.
+var expectationPrefixes = []string{
+	"Returns", "Succeeds", "Fails", "Errors", "Panics", "Rejects", "Retries", "Calls", "Skips", "Does",
+}
+
+// assertions are the testify assertion names, shared by assert, require, and suite.Suite.
+var assertions = map[string]bool{
+	"Condition": true, "Contains": true, "DirExists": true, "ElementsMatch": true, "Empty": true, "Equal": true,
+	"EqualError": true, "EqualExportedValues": true, "EqualValues": true, "Error": true, "ErrorAs": true,
+	"ErrorContains": true, "ErrorIs": true, "Eventually": true, "EventuallyWithT": true, "Exactly": true,
+	"False": true, "FileExists": true, "Greater": true, "GreaterOrEqual": true, "HTTPBodyContains": true,
+	"HTTPStatusCode": true, "Implements": true, "InDelta": true, "InEpsilon": true, "IsDecreasing": true,
+	"IsIncreasing": true, "IsType": true, "JSONEq": true, "Len": true, "Less": true, "LessOrEqual": true,
+	"Negative": true, "Never": true, "Nil": true, "NoError": true, "NotContains": true, "NotElementsMatch": true,
+	"NotEmpty": true, "NotEqual": true, "NotEqualValues": true, "NotErrorAs": true, "NotErrorIs": true,
+	"NotNil": true, "NotPanics": true, "NotSame": true, "NotSubset": true, "NotZero": true, "Panics": true,
+	"PanicsWithError": true, "PanicsWithValue": true, "Positive": true, "Regexp": true, "Same": true,
+	"Subset": true, "True": true, "WithinDuration": true, "WithinRange": true, "YAMLEq": true, "Zero": true,
+}
+
+// builtinFuncs are never the call under test.
+var builtinFuncs = map[string]bool{
+	"append": true, "cap": true, "clear": true, "close": true, "copy": true, "delete": true, "len": true,
+	"make": true, "max": true, "min": true, "new": true, "panic": true, "print": true, "println": true,
+	"recover": true,
+}
+
+// TestNames renames test functions and suite methods to TestMethod_Scenario_Expectation and updates the
+// references to them in the package.
+//
+// Method is the function the test acts on: the first call after the "// Act" comment, or else the first call
+// on sut. Expectation comes from the assertions: ErrorIs(err, errs.ErrUserNotFound) gives
+// ReturnsUserNotFoundError, Error gives ReturnsError, Panics gives Panics, and NoError gives Succeeds.
+// Scenario is what remains of the old name. When nothing remains, it is DependencyFails for an error test that
+// makes a mock return an error, InvalidInput for other error tests, and ValidInput otherwise. Tests whose
+// method or expectation cannot be derived are reported and left alone.
+type TestNames struct{}
+
+// testRename is one planned rename within a scope.
+type testRename struct {
+	pkgName string
+	method  bool
+	old     string
+	new     string
+}
+
+// NewTestNames returns the test-names codemod.
+func NewTestNames() *TestNames {
+	return &TestNames{}
+}
+
+func (r *TestNames) Rewrite(pkg *Package) ([]Change, error) {
+	var changes []Change
+	taken := r.declared(pkg)
+	var renames []testRename
+	for _, f := range pkg.Files {
+		if !f.Test {
+			continue
+		}
+		for _, decl := range f.AST.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || !r.isTest(fn) || conformingName.MatchString(fn.Name.Name) {
+				continue
+			}
+			pos := pkg.Fset.Position(fn.Name.Pos())
+			name, reason := r.derive(f, fn)
+			if name == "" {
+				changes = append(changes, Change{Pos: pos, Message: fn.Name.Name + ": " + reason, Skipped: true})
+				continue
+			}
+			scope := r.scope(f, fn)
+			if taken[scope][name] {
+				msg := fmt.Sprintf("%s: %s already exists", fn.Name.Name, name)
+				changes = append(changes, Change{Pos: pos, Message: msg, Skipped: true})
+				continue
+			}
+			if taken[scope] == nil {
+				taken[scope] = map[string]bool{}
+			}
+			taken[scope][name] = true
+			renames = append(renames, testRename{
+				pkgName: f.AST.Name.Name,
+				method:  fn.Recv != nil,
+				old:     fn.Name.Name,
+				new:     name,
+			})
+			changes = append(changes, Change{Pos: pos, Message: fmt.Sprintf("renamed %s to %s", fn.Name.Name, name)})
+			fn.Name.Name = name
+		}
+	}
+	for _, rn := range renames {
+		changes = append(changes, r.updateReferences(pkg, rn)...)
+	}
+	return changes, nil
+}
+
+// isTest reports whether fn is a test function run by go test, or a Test method of a suite. Suite runners
+// (functions calling suite.Run) and TestMain are not renamed.
+func (r *TestNames) isTest(fn *ast.FuncDecl) bool {
+	name := fn.Name.Name
+	rest, ok := strings.CutPrefix(name, "Test")
+	if !ok || rest == "" || name == "TestMain" || fn.Body == nil {
+		return false
+	}
+	if first, _ := utf8.DecodeRuneInString(rest); unicode.IsLower(first) {
+		return false
+	}
+	params := fn.Type.Params.List
+	if fn.Recv != nil {
+		return len(params) == 0 && fn.Type.Results == nil
+	}
+	if len(params) != 1 || len(params[0].Names) > 1 {
+		return false
+	}
+	star, ok := params[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	if sel, ok := star.X.(*ast.SelectorExpr); !ok || sel.Sel.Name != "T" {
+		return false
+	}
+	return !r.runsSuite(fn.Body)
+}
+
+func (r *TestNames) runsSuite(body *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Run" {
+				if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "suite" {
+					found = true
+				}
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// derive returns the conforming name for fn, or "" and the reason it cannot be derived.
+func (r *TestNames) derive(f *File, fn *ast.FuncDecl) (string, string) {
+	words := r.words(strings.TrimPrefix(fn.Name.Name, "Test"))
+	method := r.camel(r.actCall(f, fn))
+	var typeName []string
+	switch {
+	case method == "" && len(words) == 0:
+		return "", "no call after // Act and no name to keep"
+	case method == "":
+		method, words = words[0], words[1:]
+	case len(words) == 1 && !strings.HasPrefix(words[0], method):
+		// TestCreate for a test acting on Execute: the single word named the subject, not a scenario.
+		words = nil
+	case len(words) > 0 && strings.HasPrefix(words[0], method):
+		if rest := strings.TrimPrefix(words[0], method); rest != "" {
+			words = append([]string{rest}, words[1:]...)
+		} else {
+			words = words[1:]
+		}
+	case fn.Recv == nil && len(words) > 1 && words[1] == method:
+		typeName, words = words[:1], words[2:]
+	}
+
+	self := ""
+	if fn.Recv != nil {
+		self = r.self(fn)
+	}
+	expectation := r.expectation(fn.Body, self, r.imported(f, testifyAssert, testifyRequire))
+	if expectation == "" && len(words) > 0 && r.statesExpectation(words[len(words)-1]) {
+		expectation, words = words[len(words)-1], words[:len(words)-1]
+	}
+	if expectation == "" {
+		return "", "no assertion to name the expectation after"
+	}
+	scenario := strings.Join(words, "")
+	if scenario == "" {
+		scenario = r.scenario(fn.Body, expectation)
+	}
+	parts := append(append([]string{}, typeName...), method, scenario, expectation)
+	return "Test" + strings.Join(parts, "_"), ""
+}
+
+// words splits a name on underscores and capitalizes each part: "create_userOK" gives [Create UserOK].
+func (r *TestNames) words(name string) []string {
+	var words []string
+	for _, part := range strings.Split(name, "_") {
+		if part != "" {
+			words = append(words, r.camel(part))
+		}
+	}
+	return words
+}
+
+func (r *TestNames) camel(s string) string {
+	first, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return ""
+	}
+	return string(unicode.ToUpper(first)) + s[size:]
+}
+
+func (r *TestNames) statesExpectation(word string) bool {
+	for _, prefix := range expectationPrefixes {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// actCall returns the name of the function or method under test: the first call after the "// Act" comment
+// of fn, or else the first call on sut or s.sut.
+func (r *TestNames) actCall(f *File, fn *ast.FuncDecl) string {
+	act := token.NoPos
+	for _, group := range f.AST.Comments {
+		for _, c := range group.List {
+			text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+			inBody := c.Pos() > fn.Body.Lbrace && c.Pos() < fn.Body.Rbrace
+			if inBody && act == token.NoPos && (text == "Act" || strings.HasPrefix(text, "Act:")) {
+				act = c.Pos()
+			}
+		}
+	}
+	helpers := r.helpers(f, r.self(fn))
+	name := ""
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if name != "" || !ok {
+			return name == ""
+		}
+		if act != token.NoPos && call.Pos() > act && !r.helperCall(call, helpers) {
+			name = r.callee(call.Fun)
+		}
+		if act == token.NoPos && r.onSUT(call.Fun) {
+			name = r.callee(call.Fun)
+		}
+		return name == ""
+	})
+	return name
+}
+
+// helperCall reports calls that are never the one under test: builtins, assertions, and calls on one of
+// helpers, the names of the test itself and of the assert, require, mock, and context imports.
+func (r *TestNames) helperCall(call *ast.CallExpr, helpers map[string]bool) bool {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return builtinFuncs[fun.Name]
+	case *ast.SelectorExpr:
+		if x, ok := fun.X.(*ast.Ident); ok {
+			return helpers[x.Name]
+		}
+		return r.isSuiteAssertion(fun.X)
+	}
+	return false
+}
+
+// self returns the name fn refers to its test by: the suite receiver of a method, or the *testing.T
+// parameter of a function.
+func (r *TestNames) self(fn *ast.FuncDecl) string {
+	fields := fn.Type.Params
+	if fn.Recv != nil {
+		fields = fn.Recv
+	}
+	if len(fields.List) == 0 || len(fields.List[0].Names) == 0 {
+		return ""
+	}
+	return fields.List[0].Names[0].Name
+}
+
+// helpers returns the names helperCall skips calls on: self, and the names of the assert, require, mock, and
+// context imports of f.
+func (r *TestNames) helpers(f *File, self string) map[string]bool {
+	helpers := r.imported(f, testifyAssert, testifyRequire, testifyMock, "context")
+	if self != "" {
+		helpers[self] = true
+	}
+	return helpers
+}
+
+// imported returns the names f refers to the imported paths by.
+func (r *TestNames) imported(f *File, paths ...string) map[string]bool {
+	names := map[string]bool{}
+	for _, p := range paths {
+		if name, ok := f.importName(p); ok {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+func (r *TestNames) onSUT(fun ast.Expr) bool {
+	sel, ok := fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	switch x := sel.X.(type) {
+	case *ast.Ident:
+		return x.Name == "sut"
+	case *ast.SelectorExpr:
+		return x.Sel.Name == "sut"
+	}
+	return false
+}
+
+func (r *TestNames) callee(fun ast.Expr) string {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return f.Name
+	case *ast.SelectorExpr:
+		return f.Sel.Name
+	case *ast.IndexExpr:
+		return r.callee(f.X)
+	case *ast.IndexListExpr:
+		return r.callee(f.X)
+	}
+	return ""
+}
+
+// isSuiteAssertion reports whether x is s.Require() or s.Assert().
+func (r *TestNames) isSuiteAssertion(x ast.Expr) bool {
+	call, ok := x.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	return ok && (sel.Sel.Name == "Require" || sel.Sel.Name == "Assert")
+}
+
+// expectation names what the assertions of body check, or returns "" when they check nothing it can name.
+// The assertions are calls on self, the suite receiver or "", and on packages, the names of the assert and
+// require imports. Those in function literals other than subtests, such as the matcher of mock.MatchedBy, are
+// not the test's own.
+func (r *TestNames) expectation(body *ast.BlockStmt, self string, packages map[string]bool) string {
+	var errTarget ast.Expr
+	seen := map[string]bool{}
+	subtests := map[*ast.FuncLit]bool{}
+	ast.Inspect(body, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.FuncLit); ok {
+			return subtests[lit]
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if lit := r.subtest(sel, call); lit != nil {
+			subtests[lit] = true
+		}
+		offset, isAssertion := 0, r.isSuiteAssertion(sel.X)
+		if x, ok := sel.X.(*ast.Ident); ok {
+			switch {
+			case self != "" && x.Name == self:
+				isAssertion = true
+			case packages[x.Name]:
+				isAssertion, offset = true, 1
+			}
+		}
+		if !isAssertion || !assertions[sel.Sel.Name] {
+			return true
+		}
+		seen[sel.Sel.Name] = true
+		if sel.Sel.Name == "ErrorIs" && errTarget == nil && len(call.Args) > offset+1 {
+			errTarget = call.Args[offset+1]
+		}
+		return true
+	})
+
+	hasErr := seen["ErrorIs"] || seen["ErrorAs"] || seen["Error"] || seen["EqualError"] || seen["ErrorContains"]
+	switch {
+	case hasErr && seen["NoError"]:
+		return "ReturnsExpected"
+	case hasErr:
+		return "Returns" + r.errorName(errTarget)
+	case seen["Panics"] || seen["PanicsWithError"] || seen["PanicsWithValue"]:
+		return "Panics"
+	case seen["NotPanics"]:
+		return "DoesNotPanic"
+	case seen["NoError"]:
+		return "Succeeds"
+	case len(seen) == 1 && seen["True"]:
+		return "ReturnsTrue"
+	case len(seen) == 1 && seen["False"]:
+		return "ReturnsFalse"
+	case len(seen) == 1 && seen["Nil"]:
+		return "ReturnsNil"
+	case len(seen) == 1 && seen["Empty"]:
+		return "ReturnsEmpty"
+	case len(seen) > 0:
+		return "ReturnsExpected"
+	}
+	return ""
+}
+
+// subtest returns the function literal of t.Run(name, func) and s.Run(name, func), or nil.
+func (r *TestNames) subtest(sel *ast.SelectorExpr, call *ast.CallExpr) *ast.FuncLit {
+	if sel.Sel.Name != "Run" || len(call.Args) != 2 {
+		return nil
+	}
+	lit, _ := call.Args[1].(*ast.FuncLit)
+	return lit
+}
+
+// errorName turns a sentinel such as errs.ErrUserNotFound into UserNotFoundError, and anything else into
+// Error.
+func (r *TestNames) errorName(target ast.Expr) string {
+	name := r.callee(target)
+	if rest, ok := strings.CutPrefix(name, "Err"); ok && rest != "" && unicode.IsUpper([]rune(rest)[0]) {
+		return rest + "Error"
+	}
+	return "Error"
+}
+
+func (r *TestNames) scenario(body *ast.BlockStmt, expectation string) string {
+	if !strings.HasSuffix(expectation, "Error") {
+		return "ValidInput"
+	}
+	if r.returnsMockError(body) {
+		return "DependencyFails"
+	}
+	return "InvalidInput"
+}
+
+// returnsMockError reports whether body programs a mock to return an error: a Return call with an argument
+// built by errors.New or fmt.Errorf, or named like an error (err, repoErr, ErrX).
+func (r *TestNames) returnsMockError(body *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || found {
+			return !found
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); !ok || sel.Sel.Name != "Return" {
+			return true
+		}
+		for _, arg := range call.Args {
+			name := r.callee(arg)
+			if inner, ok := arg.(*ast.CallExpr); ok {
+				name = r.callee(inner.Fun)
+				found = found || name == "New" || name == "Errorf"
+				continue
+			}
+			found = found || name == "err" || strings.HasSuffix(name, "Err") || strings.HasPrefix(name, "Err")
+		}
+		return !found
+	})
+	return found
+}
+
+// declared returns the function names declared per scope: the package name for functions, and
+// "<package>.<receiver>" for methods.
+func (r *TestNames) declared(pkg *Package) map[string]map[string]bool {
+	taken := map[string]map[string]bool{}
+	for _, f := range pkg.Files {
+		for _, decl := range f.AST.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			scope := r.scope(f, fn)
+			if taken[scope] == nil {
+				taken[scope] = map[string]bool{}
+			}
+			taken[scope][fn.Name.Name] = true
+		}
+	}
+	return taken
+}
+
+func (r *TestNames) scope(f *File, fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return f.AST.Name.Name
+	}
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	return f.AST.Name.Name + "." + r.callee(recv)
+}
+
+// updateReferences renames the uses of a renamed test in the files of its package: identifiers for a
+// function, selectors for a method.
+func (r *TestNames) updateReferences(pkg *Package, rn testRename) []Change {
+	var changes []Change
+	for _, f := range pkg.Files {
+		if f.AST.Name.Name != rn.pkgName {
+			continue
+		}
+		ast.Inspect(f.AST, func(n ast.Node) bool {
+			var ident *ast.Ident
+			switch x := n.(type) {
+			case *ast.SelectorExpr:
+				if rn.method {
+					ident = x.Sel
+				}
+			case *ast.Ident:
+				if !rn.method {
+					ident = x
+				}
+			}
+			if ident != nil && ident.Name == rn.old {
+				ident.Name = rn.new
+				msg := fmt.Sprintf("updated reference to %s", rn.new)
+				changes = append(changes, Change{Pos: pkg.Fset.Position(ident.Pos()), Message: msg})
+			}
+			return true
+		})
+	}
+	return changes
+}
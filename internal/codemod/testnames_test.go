@@ -0,0 +1,11 @@
+package codemod_test
+
+import (
+	"testing"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/codemod"
+)
+
+func TestTestNames_Rewrite_Packages_MatchGolden(t *testing.T) {
+	matchGolden(t, codemod.NewTestNames(), "testnames")
+}
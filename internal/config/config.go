@@ -0,0 +1,98 @@
+// Package config loads ai-rules.yaml, the per-project settings shared by the ai-rules commands.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// FileName is the configuration file looked up in the target module root.
+const FileName = "ai-rules.yaml"
+
+var (
+	// ErrUnknownKey is returned for keys ai-rules.yaml does not define, so typos fail loudly.
+	ErrUnknownKey = errors.New("unknown key")
+	// ErrInvalidValue is returned when a key has the wrong type or is out of range.
+	ErrInvalidValue = errors.New("invalid value")
+)
+
+// Unit test flavors. Each selects one variant of the go-unit-tests skill.
+const (
+	FlavorTestify = "testify"
+	FlavorGomock  = "gomock"
+	FlavorStdlib  = "stdlib"
+	FlavorGinkgo  = "ginkgo"
+)
+
+// Flavors lists the accepted unit_tests.flavor values.
+var Flavors = []string{FlavorTestify, FlavorGomock, FlavorStdlib, FlavorGinkgo}
+
+// Config is the decoded ai-rules.yaml.
+type Config struct {
+	Coverage  Coverage
+	UnitTests UnitTests
+}
+
+// Coverage configures the coverage policy command.
+type Coverage struct {
+	// Threshold is the minimum statement coverage, in percent, for packages without an override.
+	Threshold float64
+	// Packages maps module-relative package paths to their own threshold. A trailing "/..." applies the
+	// threshold to the package and everything below it; the longest match wins.
+	Packages map[string]float64
+	// Exclude lists module-relative file globs ignored by the policy. "**" matches any number of directories.
+	Exclude []string
+	// FailOnUncoveredExported makes an exported function without a single covered statement a policy failure
+	// instead of a warning.
+	FailOnUncoveredExported bool
+}
+
+// UnitTests configures which unit-testing style the project follows.
+type UnitTests struct {
+	// Flavor is one of Flavors. Exported skills include only the go-unit-tests variant for this flavor, so
+	// assistants never mix styles within one repository.
+	Flavor string
+}
+
+// Default returns the configuration used when ai-rules.yaml is absent.
+func Default() Config {
+	return Config{
+		Coverage: Coverage{
+			Threshold: 80,
+			Packages:  map[string]float64{},
+			Exclude:   []string{"test/**", "**/mocks/**"},
+		},
+		UnitTests: UnitTests{Flavor: FlavorTestify},
+	}
+}
+
+// Load reads and decodes the file at path. Keys missing from the file keep their Default values.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("read config: %w", err)
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return Config{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse decodes ai-rules.yaml content on top of Default.
+func Parse(data []byte) (Config, error) {
+	parser, err := newYAMLParser(data)
+	if err != nil {
+		return Config{}, err
+	}
+	doc, err := parser.parse()
+	if err != nil {
+		return Config{}, err
+	}
+	cfg := Default()
+	if err := newDecoder().decode(doc, &cfg); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,100 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/config"
+)
+
+func TestParse_FullDocument_OverridesDefaults(t *testing.T) {
+	// Arrange
+	doc := `
+coverage:
+  threshold: 85%
+  packages:
+    ./internal/...: 90
+    cmd/ai-rules: 0
+  exclude: ["**/*_gen.go"]
+  fail_on_uncovered_exported: true
+unit_tests:
+  flavor: stdlib
+`
+
+	// Act
+	cfg, err := config.Parse([]byte(doc))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := config.Config{
+		Coverage: config.Coverage{
+			Threshold:               85,
+			Packages:                map[string]float64{"internal/...": 90, "cmd/ai-rules": 0},
+			Exclude:                 []string{"**/*_gen.go"},
+			FailOnUncoveredExported: true,
+		},
+		UnitTests: config.UnitTests{Flavor: config.FlavorStdlib},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+}
+
+func TestParse_EmptyDocument_ReturnsDefault(t *testing.T) {
+	// Act
+	cfg, err := config.Parse(nil)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !reflect.DeepEqual(cfg, config.Default()) {
+		t.Errorf("got %+v, want %+v", cfg, config.Default())
+	}
+}
+
+func TestParse_InvalidDocument_ReturnsError(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want error
+	}{
+		{name: "unknown top-level key", doc: "coverag:\n  threshold: 80\n", want: config.ErrUnknownKey},
+		{name: "unknown nested key", doc: "coverage:\n  treshold: 80\n", want: config.ErrUnknownKey},
+		{name: "threshold above 100", doc: "coverage:\n  threshold: 101\n", want: config.ErrInvalidValue},
+		{name: "threshold not a number", doc: "coverage:\n  threshold: high\n", want: config.ErrInvalidValue},
+		{name: "package threshold", doc: "coverage:\n  packages:\n    a: -1\n", want: config.ErrInvalidValue},
+		{name: "exclude not a list", doc: "coverage:\n  exclude: a\n", want: config.ErrInvalidValue},
+		{name: "empty exclude item", doc: "coverage:\n  exclude: ['']\n", want: config.ErrInvalidValue},
+		{name: "bool", doc: "coverage:\n  fail_on_uncovered_exported: yes\n", want: config.ErrInvalidValue},
+		{name: "flavor", doc: "unit_tests:\n  flavor: ava\n", want: config.ErrInvalidValue},
+		{name: "section not a mapping", doc: "coverage: 80\n", want: config.ErrInvalidValue},
+		{name: "syntax", doc: "coverage:\n\tthreshold: 80\n", want: config.ErrInvalidYAML},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := config.Parse([]byte(tt.doc))
+
+			// Assert
+			if !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_MissingFile_ReturnsNotExist(t *testing.T) {
+	// Act
+	_, err := config.Load(filepath.Join(t.TempDir(), config.FileName))
+
+	// Assert
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("error = %v, want %v", err, os.ErrNotExist)
+	}
+}
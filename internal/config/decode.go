@@ -0,0 +1,165 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// decoder maps the generic YAML tree onto Config, reporting errors with the dotted key path.
+type decoder struct{}
+
+func newDecoder() *decoder {
+	return &decoder{}
+}
+
+func (d *decoder) decode(doc any, cfg *Config) error {
+	root, err := d.mapping("", doc)
+	if err != nil {
+		return err
+	}
+	for key, value := range root {
+		switch key {
+		case "coverage":
+			if err := d.decodeCoverage("coverage", value, &cfg.Coverage); err != nil {
+				return err
+			}
+		case "unit_tests":
+			if err := d.decodeUnitTests("unit_tests", value, &cfg.UnitTests); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%w: %s", ErrUnknownKey, key)
+		}
+	}
+	return nil
+}
+
+func (d *decoder) decodeCoverage(path string, value any, cov *Coverage) error {
+	fields, err := d.mapping(path, value)
+	if err != nil {
+		return err
+	}
+	for key, v := range fields {
+		keyPath := path + "." + key
+		switch key {
+		case "threshold":
+			if cov.Threshold, err = d.percent(keyPath, v); err != nil {
+				return err
+			}
+		case "packages":
+			packages, err := d.mapping(keyPath, v)
+			if err != nil {
+				return err
+			}
+			cov.Packages = make(map[string]float64, len(packages))
+			for pkg, threshold := range packages {
+				pct, err := d.percent(keyPath+"."+pkg, threshold)
+				if err != nil {
+					return err
+				}
+				cov.Packages[strings.TrimPrefix(pkg, "./")] = pct
+			}
+		case "exclude":
+			if cov.Exclude, err = d.strings(keyPath, v); err != nil {
+				return err
+			}
+		case "fail_on_uncovered_exported":
+			if cov.FailOnUncoveredExported, err = d.bool(keyPath, v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%w: %s", ErrUnknownKey, keyPath)
+		}
+	}
+	return nil
+}
+
+func (d *decoder) decodeUnitTests(path string, value any, ut *UnitTests) error {
+	fields, err := d.mapping(path, value)
+	if err != nil {
+		return err
+	}
+	for key, v := range fields {
+		keyPath := path + "." + key
+		switch key {
+		case "flavor":
+			if ut.Flavor, err = d.oneOf(keyPath, v, Flavors); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%w: %s", ErrUnknownKey, keyPath)
+		}
+	}
+	return nil
+}
+
+func (d *decoder) mapping(path string, value any) (map[string]any, error) {
+	if value == nil {
+		return map[string]any{}, nil
+	}
+	m, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s must be a mapping", ErrInvalidValue, d.name(path))
+	}
+	return m, nil
+}
+
+func (d *decoder) strings(path string, value any) ([]string, error) {
+	if value == nil {
+		return []string{}, nil
+	}
+	items, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s must be a list", ErrInvalidValue, path)
+	}
+	out := make([]string, 0, len(items))
+	for i, item := range items {
+		s, ok := item.(string)
+		if !ok || s == "" {
+			return nil, fmt.Errorf("%w: %s[%d] must be a non-empty string", ErrInvalidValue, path, i)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func (d *decoder) percent(path string, value any) (float64, error) {
+	s, ok := value.(string)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s must be a number", ErrInvalidValue, path)
+	}
+	pct, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil || pct < 0 || pct > 100 {
+		return 0, fmt.Errorf("%w: %s must be a percentage between 0 and 100, got %q", ErrInvalidValue, path, s)
+	}
+	return pct, nil
+}
+
+func (d *decoder) bool(path string, value any) (bool, error) {
+	s, _ := value.(string)
+	switch s {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	default:
+		return false, fmt.Errorf("%w: %s must be true or false", ErrInvalidValue, path)
+	}
+}
+
+func (d *decoder) oneOf(path string, value any, allowed []string) (string, error) {
+	s, _ := value.(string)
+	if !slices.Contains(allowed, s) {
+		return "", fmt.Errorf("%w: %s must be one of %s", ErrInvalidValue, path, strings.Join(allowed, ", "))
+	}
+	return s, nil
+}
+
+func (d *decoder) name(path string) string {
+	if path == "" {
+		return "document"
+	}
+	return path
+}
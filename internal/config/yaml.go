@@ -0,0 +1,254 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidYAML is returned when ai-rules.yaml uses syntax outside the supported subset.
+var ErrInvalidYAML = errors.New("invalid yaml")
+
+// yamlLine is one significant (non-blank, non-comment) line of the document.
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// yamlParser reads the YAML subset used by ai-rules.yaml: block mappings, block sequences of scalars or
+// mappings, flow sequences of scalars ([a, b]), plain and quoted scalars, and # comments. Anchors, multi-line
+// scalars, and flow mappings are not supported.
+//
+// Mappings decode to map[string]any, sequences to []any, scalars to string, and empty values to nil.
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+func newYAMLParser(data []byte) (*yamlParser, error) {
+	p := &yamlParser{}
+	for i, raw := range strings.Split(string(data), "\n") {
+		raw = strings.TrimRight(raw, " \t\r")
+		if strings.ContainsRune(raw, '\t') && strings.TrimLeft(raw, "\t") != raw {
+			return nil, fmt.Errorf("%w: line %d: tabs are not allowed for indentation", ErrInvalidYAML, i+1)
+		}
+		text := p.stripComment(strings.TrimLeft(raw, " "))
+		if text == "" || text == "---" {
+			continue
+		}
+		p.lines = append(p.lines, yamlLine{number: i + 1, indent: len(raw) - len(strings.TrimLeft(raw, " ")), text: text})
+	}
+	return p, nil
+}
+
+func (p *yamlParser) parse() (any, error) {
+	if len(p.lines) == 0 {
+		return map[string]any{}, nil
+	}
+	value, err := p.parseBlock(p.lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		return nil, fmt.Errorf("%w: line %d: unexpected indentation", ErrInvalidYAML, line.number)
+	}
+	return value, nil
+}
+
+func (p *yamlParser) parseBlock(indent int) (any, error) {
+	if strings.HasPrefix(p.lines[p.pos].text, "- ") || p.lines[p.pos].text == "-" {
+		return p.parseSequence(indent)
+	}
+	return p.parseMapping(indent)
+}
+
+func (p *yamlParser) parseMapping(indent int) (map[string]any, error) {
+	result := map[string]any{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, fmt.Errorf("%w: line %d: unexpected indentation", ErrInvalidYAML, line.number)
+		}
+		key, rest, err := p.splitKey(line)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := result[key]; dup {
+			return nil, fmt.Errorf("%w: line %d: duplicate key %q", ErrInvalidYAML, line.number, key)
+		}
+		p.pos++
+		value, err := p.parseValue(line, indent, rest)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+func (p *yamlParser) parseSequence(indent int) ([]any, error) {
+	result := []any{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, fmt.Errorf("%w: line %d: unexpected indentation", ErrInvalidYAML, line.number)
+		}
+		if !strings.HasPrefix(line.text, "- ") && line.text != "-" {
+			return nil, fmt.Errorf("%w: line %d: expected sequence item", ErrInvalidYAML, line.number)
+		}
+		item := strings.TrimSpace(strings.TrimPrefix(line.text, "-"))
+		if item == "" {
+			p.pos++
+			value, err := p.parseNested(line, indent)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+			continue
+		}
+		if _, _, err := p.splitKey(yamlLine{number: line.number, text: item}); err == nil && !p.isQuoted(item) {
+			// "- key: value" starts a mapping whose keys are indented to the first key's column.
+			itemIndent := line.indent + len(line.text) - len(item)
+			p.lines[p.pos] = yamlLine{number: line.number, indent: itemIndent, text: item}
+			value, err := p.parseMapping(itemIndent)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+			continue
+		}
+		p.pos++
+		scalar, err := p.parseInline(line, item)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, scalar)
+	}
+	return result, nil
+}
+
+// parseValue decodes the value after "key:": inline when present, otherwise the nested block, otherwise nil.
+func (p *yamlParser) parseValue(line yamlLine, indent int, rest string) (any, error) {
+	if rest != "" {
+		return p.parseInline(line, rest)
+	}
+	return p.parseNested(line, indent)
+}
+
+func (p *yamlParser) parseNested(line yamlLine, indent int) (any, error) {
+	if p.pos >= len(p.lines) {
+		return nil, nil
+	}
+	next := p.lines[p.pos]
+	if next.indent > indent {
+		return p.parseBlock(next.indent)
+	}
+	// A sequence may sit at the same indentation as its parent key.
+	if next.indent == indent && strings.HasPrefix(next.text, "- ") && !strings.HasPrefix(line.text, "-") {
+		return p.parseSequence(indent)
+	}
+	return nil, nil
+}
+
+func (p *yamlParser) parseInline(line yamlLine, text string) (any, error) {
+	if strings.HasPrefix(text, "[") {
+		if !strings.HasSuffix(text, "]") {
+			return nil, fmt.Errorf("%w: line %d: unterminated flow sequence", ErrInvalidYAML, line.number)
+		}
+		inner := strings.TrimSpace(text[1 : len(text)-1])
+		items := []any{}
+		if inner == "" {
+			return items, nil
+		}
+		for _, part := range strings.Split(inner, ",") {
+			scalar, err := p.parseScalar(line, strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, scalar)
+		}
+		return items, nil
+	}
+	if strings.HasPrefix(text, "{") {
+		if text == "{}" {
+			return map[string]any{}, nil
+		}
+		return nil, fmt.Errorf("%w: line %d: flow mappings are not supported", ErrInvalidYAML, line.number)
+	}
+	if text == "~" || text == "null" {
+		return nil, nil
+	}
+	return p.parseScalar(line, text)
+}
+
+func (p *yamlParser) parseScalar(line yamlLine, text string) (string, error) {
+	if len(text) >= 2 && text[0] == '"' && text[len(text)-1] == '"' {
+		return strings.NewReplacer(`\"`, `"`, `\\`, `\`, `\n`, "\n", `\t`, "\t").Replace(text[1 : len(text)-1]), nil
+	}
+	if len(text) >= 2 && text[0] == '\'' && text[len(text)-1] == '\'' {
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	}
+	if strings.HasPrefix(text, "\"") || strings.HasPrefix(text, "'") {
+		return "", fmt.Errorf("%w: line %d: unterminated quoted scalar", ErrInvalidYAML, line.number)
+	}
+	if strings.HasPrefix(text, "&") || strings.HasPrefix(text, "*") || text == "|" || text == ">" {
+		return "", fmt.Errorf("%w: line %d: anchors and block scalars are not supported", ErrInvalidYAML, line.number)
+	}
+	return text, nil
+}
+
+// splitKey splits "key: rest" or "key:". Keys may be quoted.
+func (p *yamlParser) splitKey(line yamlLine) (string, string, error) {
+	text := line.text
+	if p.isQuoted(text) {
+		quote := text[0]
+		end := strings.IndexByte(text[1:], quote)
+		if end < 0 || !strings.HasPrefix(text[end+2:], ":") {
+			return "", "", fmt.Errorf("%w: line %d: expected quoted key followed by ':'", ErrInvalidYAML, line.number)
+		}
+		return text[1 : end+1], strings.TrimSpace(text[end+3:]), nil
+	}
+	idx := strings.Index(text, ": ")
+	if idx < 0 {
+		if !strings.HasSuffix(text, ":") {
+			return "", "", fmt.Errorf("%w: line %d: expected 'key: value'", ErrInvalidYAML, line.number)
+		}
+		idx = len(text) - 1
+	}
+	key := strings.TrimSpace(text[:idx])
+	if key == "" {
+		return "", "", fmt.Errorf("%w: line %d: empty key", ErrInvalidYAML, line.number)
+	}
+	return key, strings.TrimSpace(text[idx+1:]), nil
+}
+
+func (p *yamlParser) isQuoted(text string) bool {
+	return strings.HasPrefix(text, "\"") || strings.HasPrefix(text, "'")
+}
+
+// stripComment removes a trailing "# comment" that is not inside quotes.
+func (p *yamlParser) stripComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" [,", text[i-1]) >= 0):
+			quote = c
+		case c == '#' && (i == 0 || text[i-1] == ' '):
+			return strings.TrimRight(text[:i], " ")
+		}
+	}
+	return text
+}
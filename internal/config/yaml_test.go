@@ -0,0 +1,120 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestYAMLParser_Parse_SupportedSubset_ReturnsTree(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want any
+	}{
+		{
+			name: "empty document",
+			doc:  "# only a comment\n---\n",
+			want: map[string]any{},
+		},
+		{
+			name: "nested mappings",
+			doc:  "coverage:\n  packages:\n    internal/...: 90\n    cmd: 50\n",
+			want: map[string]any{"coverage": map[string]any{
+				"packages": map[string]any{"internal/...": "90", "cmd": "50"},
+			}},
+		},
+		{
+			name: "flow sequence",
+			doc:  "exclude: [test/**, \"**/mocks/**\", 'a b']\n",
+			want: map[string]any{"exclude": []any{"test/**", "**/mocks/**", "a b"}},
+		},
+		{
+			name: "empty flow sequence and mapping",
+			doc:  "exclude: []\npackages: {}\n",
+			want: map[string]any{"exclude": []any{}, "packages": map[string]any{}},
+		},
+		{
+			name: "block sequence at the key's indentation",
+			doc:  "exclude:\n- a\n- b\n",
+			want: map[string]any{"exclude": []any{"a", "b"}},
+		},
+		{
+			name: "sequence of mappings",
+			doc:  "items:\n  - name: a\n    size: 1\n  - name: b\n",
+			want: map[string]any{"items": []any{
+				map[string]any{"name": "a", "size": "1"},
+				map[string]any{"name": "b"},
+			}},
+		},
+		{
+			name: "comments",
+			doc:  "# header\nthreshold: 80 # percent\nname: \"a # b\"\n  # indented comment\n",
+			want: map[string]any{"threshold": "80", "name": "a # b"},
+		},
+		{
+			name: "null and empty values",
+			doc:  "a: ~\nb: null\nc:\n",
+			want: map[string]any{"a": nil, "b": nil, "c": nil},
+		},
+		{
+			name: "quoted keys and escapes",
+			doc:  "\"a: b\": \"x\\ty\"\n'c': 'it''s'\n",
+			want: map[string]any{"a: b": "x\ty", "c": "it's"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			p, err := newYAMLParser([]byte(tt.doc))
+			if err != nil {
+				t.Fatalf("newYAMLParser: %v", err)
+			}
+
+			// Act
+			got, err := p.parse()
+
+			// Assert
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestYAMLParser_Parse_UnsupportedSyntax_ReturnsInvalidYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{name: "tab indentation", doc: "a:\n\tb: 1\n"},
+		{name: "unexpected indentation", doc: "a: 1\n  b: 2\n"},
+		{name: "duplicate key", doc: "a: 1\na: 2\n"},
+		{name: "missing colon", doc: "a\n"},
+		{name: "unterminated flow sequence", doc: "a: [b, c\n"},
+		{name: "flow mapping", doc: "a: {b: c}\n"},
+		{name: "unterminated quote", doc: "a: \"b\n"},
+		{name: "anchor", doc: "a: &b c\n"},
+		{name: "block scalar", doc: "a: |\n"},
+		{name: "mixed sequence", doc: "a:\n  - b\n  c: d\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			p, err := newYAMLParser([]byte(tt.doc))
+
+			// Act
+			if err == nil {
+				_, err = p.parse()
+			}
+
+			// Assert
+			if !errors.Is(err, ErrInvalidYAML) {
+				t.Errorf("error = %v, want %v", err, ErrInvalidYAML)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package coverage
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Excluder matches module-relative file paths against ai-rules.yaml exclude globs.
+//
+// "*" matches within one path segment, "**" matches any number of segments, and "?" matches one character.
+type Excluder struct {
+	patterns []*regexp.Regexp
+}
+
+// NewExcluder compiles the glob patterns.
+func NewExcluder(globs []string) (*Excluder, error) {
+	e := &Excluder{patterns: make([]*regexp.Regexp, 0, len(globs))}
+	for _, glob := range globs {
+		re, err := regexp.Compile(e.toRegexp(strings.TrimPrefix(glob, "./")))
+		if err != nil {
+			return nil, fmt.Errorf("compile exclude pattern %q: %w", glob, err)
+		}
+		e.patterns = append(e.patterns, re)
+	}
+	return e, nil
+}
+
+// Excluded reports whether rel matches any pattern.
+func (e *Excluder) Excluded(rel string) bool {
+	for _, re := range e.patterns {
+		if re.MatchString(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Excluder) toRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == '*' && i+1 < len(glob) && glob[i+1] == '*':
+			i++
+			if i+1 < len(glob) && glob[i+1] == '/' {
+				// "**/" matches zero or more leading directories.
+				i++
+				b.WriteString("(?:.*/)?")
+			} else {
+				b.WriteString(".*")
+			}
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package coverage_test
+
+import (
+	"testing"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/coverage"
+)
+
+func TestExcluder_Excluded_Globs_MatchPaths(t *testing.T) {
+	tests := []struct {
+		name string
+		glob string
+		rel  string
+		want bool
+	}{
+		{name: "star within a segment", glob: "internal/*.go", rel: "internal/a.go", want: true},
+		{name: "star stops at a slash", glob: "internal/*.go", rel: "internal/x/a.go", want: false},
+		{name: "double star in the middle", glob: "**/mocks/**", rel: "internal/mocks/a.go", want: true},
+		{name: "leading double star matches no directory", glob: "**/mocks/**", rel: "mocks/a.go", want: true},
+		{name: "trailing double star", glob: "test/**", rel: "test/a/b/c.go", want: true},
+		{name: "trailing double star needs the prefix", glob: "test/**", rel: "testdata/a.go", want: false},
+		{name: "question mark", glob: "a?.go", rel: "ab.go", want: true},
+		{name: "question mark is one character", glob: "a?.go", rel: "abc.go", want: false},
+		{name: "dot slash prefix", glob: "./cmd/*", rel: "cmd/main.go", want: true},
+		{name: "dots are literal", glob: "*.pb.go", rel: "apbxgo", want: false},
+		{name: "suffix", glob: "**/*_gen.go", rel: "a/b/model_gen.go", want: true},
+		{name: "anchored", glob: "cmd/*", rel: "x/cmd/main.go", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			e, err := coverage.NewExcluder([]string{tt.glob})
+			if err != nil {
+				t.Fatalf("NewExcluder: %v", err)
+			}
+
+			// Act
+			got := e.Excluded(tt.rel)
+
+			// Assert
+			if got != tt.want {
+				t.Errorf("Excluded(%q) with %q = %v, want %v", tt.rel, tt.glob, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExcluder_Excluded_NoPatterns_ReturnsFalse(t *testing.T) {
+	// Arrange
+	e, err := coverage.NewExcluder(nil)
+	if err != nil {
+		t.Fatalf("NewExcluder: %v", err)
+	}
+
+	// Act
+	got := e.Excluded("a.go")
+
+	// Assert
+	if got {
+		t.Error("Excluded(\"a.go\") = true, want false")
+	}
+}
@@ -0,0 +1,231 @@
+package coverage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+
+	"github.com/cristiano-pacheco/ai-rules/generator"
+)
+
+// GapKind is what a gap leaves untested.
+type GapKind string
+
+const (
+	// GapExported is an exported function or method with no covered statement.
+	GapExported GapKind = "exported_function"
+	// GapErrorBranch is an error-returning branch no test takes, in a function that tests do reach.
+	GapErrorBranch GapKind = "error_branch"
+)
+
+// Rule is the skill rule a gap breaks.
+type Rule struct {
+	Skill string `json:"skill"`
+	Text  string `json:"text"`
+}
+
+// The rules gaps are mapped to, quoted from the skills that state them.
+var (
+	ruleTestExported = Rule{
+		Skill: "go-coverage-policy",
+		Text:  "Every reported uncovered exported function gets a test or is deleted",
+	}
+	ruleAssertCause = Rule{
+		Skill: "go-error-handling",
+		Text:  "Passes unknown errors through: make the dependency fail and assert ErrorIs(err, cause)",
+	}
+	ruleErrorCondition = Rule{
+		Skill: "go-unit-tests",
+		Text:  "Test cases: happy path, error conditions, and edge cases",
+	}
+)
+
+// GapReport lists the code no test reaches, each gap with the rule it breaks.
+type GapReport struct {
+	Module string `json:"module"`
+	Gaps   []Gap  `json:"gaps"`
+}
+
+// Gap is one untested function or error branch.
+type Gap struct {
+	Kind    GapKind `json:"kind"`
+	Package string  `json:"package"`
+	File    string  `json:"file"`
+	Line    int     `json:"line"`
+	// Func is "Func" for functions and "Type.Method" for methods.
+	Func       string `json:"func"`
+	Statements int    `json:"statements"`
+	Rule       Rule   `json:"rule"`
+	// Scaffold is the ai-rules command that generates a test skeleton for the gap. It is empty for unexported
+	// functions, which are tested through the exported ones, and for the ones scaffold generates no tests
+	// for, such as generic functions and methods of generic types.
+	Scaffold string `json:"scaffold,omitempty"`
+}
+
+// Gaps lists the exported functions no test reaches and, in the functions tests do reach, the branches
+// returning an error that no test takes. Generated and excluded files are skipped, as in Evaluate.
+func (p *Policy) Gaps(blocks []Block) (*GapReport, error) {
+	report := &GapReport{Module: p.module.Path}
+	files, byFile := p.group(blocks)
+	targets := map[string]map[string]string{}
+	for _, rel := range files {
+		if p.excluder.Excluded(rel) {
+			continue
+		}
+		sf, err := p.sources.file(rel)
+		if err != nil {
+			return nil, err
+		}
+		if sf.generated {
+			continue
+		}
+		pkg := path.Dir(rel)
+		if _, ok := targets[pkg]; !ok {
+			if targets[pkg], err = p.scaffoldTargets(pkg); err != nil {
+				return nil, err
+			}
+		}
+		report.Gaps = append(report.Gaps, p.fileGaps(pkg, rel, sf, byFile[rel], targets[pkg])...)
+	}
+	return report, nil
+}
+
+// fileGaps lists the gaps of one file. targets maps function names to the -only value of their scaffold
+// command.
+func (p *Policy) fileGaps(pkg, rel string, sf *sourceFile, blocks []Block, targets map[string]string) []Gap {
+	var gaps []Gap
+	reached := map[string]bool{}
+	exported := map[string]bool{}
+	for _, fn := range sf.funcs {
+		statements, covered := p.count(fn.span, blocks)
+		reached[fn.name] = covered > 0
+		exported[fn.name] = fn.exported
+		if !fn.exported || statements == 0 || covered > 0 {
+			continue
+		}
+		gaps = append(gaps, Gap{
+			Kind:       GapExported,
+			Package:    pkg,
+			File:       rel,
+			Line:       fn.line,
+			Func:       fn.name,
+			Statements: statements,
+			Rule:       ruleTestExported,
+			Scaffold:   p.scaffold(pkg, targets[fn.name]),
+		})
+	}
+	for _, branch := range sf.branches {
+		statements, covered := p.count(branch.span, blocks)
+		if !reached[branch.fn] || statements == 0 || covered > 0 {
+			continue
+		}
+		gap := Gap{
+			Kind:       GapErrorBranch,
+			Package:    pkg,
+			File:       rel,
+			Line:       branch.line,
+			Func:       branch.fn,
+			Statements: statements,
+			Rule:       ruleErrorCondition,
+		}
+		if branch.cause {
+			gap.Rule = ruleAssertCause
+		}
+		if exported[branch.fn] {
+			gap.Scaffold = p.scaffold(pkg, targets[branch.fn])
+		}
+		gaps = append(gaps, gap)
+	}
+	sort.SliceStable(gaps, func(i, j int) bool { return gaps[i].Line < gaps[j].Line })
+	return gaps
+}
+
+// count returns the statements inside s and how many of them ran.
+func (p *Policy) count(s span, blocks []Block) (statements, covered int) {
+	for _, b := range blocks {
+		if !s.contains(b) {
+			continue
+		}
+		statements += b.NumStmt
+		if b.Count > 0 {
+			covered += b.NumStmt
+		}
+	}
+	return statements, covered
+}
+
+// scaffold returns the scaffold command for a -only value, or "" when there is none.
+func (p *Policy) scaffold(pkg, only string) string {
+	if only == "" {
+		return ""
+	}
+	return fmt.Sprintf("ai-rules scaffold -dir %s -only %s", pkg, only)
+}
+
+// scaffoldTargets maps the exported functions of the module-relative package directory that scaffold
+// generates tests for to the -only value selecting them: Func and Type.Method for themselves, and the type
+// for a constructor, which is tested through it. It reads the package as scaffold does, so every suggested
+// command names a subject scaffold finds.
+func (p *Policy) scaffoldTargets(pkg string) (map[string]string, error) {
+	files, _, err := generator.ParseDir(p.module.Abs(pkg))
+	if err != nil {
+		return nil, fmt.Errorf("find scaffold subjects of %s: %w", pkg, err)
+	}
+
+	subjects, _ := generator.Inspect(nil, files...)
+	targets := map[string]string{}
+	for _, s := range subjects {
+		if s.Kind == generator.KindFunc {
+			targets[s.Name] = s.Name
+			continue
+		}
+		if s.Constructor != nil {
+			targets[s.Constructor.Name] = s.Name
+		}
+		for _, m := range s.Methods {
+			targets[s.Name+"."+m.Name] = s.Name + "." + m.Name
+		}
+	}
+	return targets, nil
+}
+
+// WriteText writes one entry per gap, with its rule and scaffold command, followed by a summary line.
+func (r *GapReport) WriteText(w io.Writer) error {
+	exported, branches := 0, 0
+	for _, gap := range r.Gaps {
+		what := "exported, no test calls it"
+		if gap.Kind == GapErrorBranch {
+			branches++
+			what = "error branch, no test takes it"
+		} else {
+			exported++
+		}
+		fmt.Fprintf(w, "%s:%d\t%s\t%s\n", gap.File, gap.Line, gap.Func, what)
+		fmt.Fprintf(w, "    rule (%s): %s\n", gap.Rule.Skill, gap.Rule.Text)
+		if gap.Scaffold != "" {
+			fmt.Fprintf(w, "    scaffold: %s\n", gap.Scaffold)
+		}
+	}
+
+	summary := "coverage gaps: none"
+	if len(r.Gaps) > 0 {
+		summary = fmt.Sprintf("coverage gaps: %d exported functions and %d error branches without a test",
+			exported, branches)
+	}
+	if _, err := fmt.Fprintln(w, summary); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+	return nil
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *GapReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return fmt.Errorf("encode report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package coverage
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/config"
+	"github.com/cristiano-pacheco/ai-rules/internal/gomod"
+)
+
+// Policy applies the coverage section of ai-rules.yaml to a parsed profile.
+type Policy struct {
+	cfg      config.Coverage
+	module   *gomod.Module
+	excluder *Excluder
+	sources  *sourceIndex
+	// packages holds the configured thresholds keyed by module-relative path.
+	packages map[string]float64
+}
+
+// NewPolicy builds a policy for the module. Package keys in cfg may be module-relative or full import paths.
+func NewPolicy(cfg config.Coverage, module *gomod.Module) (*Policy, error) {
+	excluder, err := NewExcluder(cfg.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	p := &Policy{
+		cfg:      cfg,
+		module:   module,
+		excluder: excluder,
+		sources:  newSourceIndex(module),
+		packages: make(map[string]float64, len(cfg.Packages)),
+	}
+	for key, threshold := range cfg.Packages {
+		if rel, ok := module.Rel(key); ok {
+			key = rel
+		}
+		p.packages[key] = threshold
+	}
+	return p, nil
+}
+
+// Evaluate computes per-package coverage, compares it with the thresholds, and lists exported functions
+// that no test reaches. Generated files (those with a "Code generated ... DO NOT EDIT." header), excluded
+// files, and files from other modules are left out of every number.
+func (p *Policy) Evaluate(blocks []Block) (*Report, error) {
+	report := &Report{Module: p.module.Path, FailOnUncoveredExported: p.cfg.FailOnUncoveredExported}
+	files, byFile := p.group(blocks)
+
+	packages := map[string]*PackageResult{}
+	for _, rel := range files {
+		if p.excluder.Excluded(rel) {
+			report.Excluded = append(report.Excluded, rel)
+			continue
+		}
+		sf, err := p.sources.file(rel)
+		if err != nil {
+			return nil, err
+		}
+		if sf.generated {
+			report.Generated = append(report.Generated, rel)
+			continue
+		}
+
+		pkg := path.Dir(rel)
+		result, ok := packages[pkg]
+		if !ok {
+			result = &PackageResult{Path: pkg, Threshold: p.threshold(pkg)}
+			packages[pkg] = result
+		}
+		fileBlocks := byFile[rel]
+		for _, b := range fileBlocks {
+			result.Statements += b.NumStmt
+			if b.Count > 0 {
+				result.Covered += b.NumStmt
+			}
+		}
+		report.Uncovered = append(report.Uncovered, p.uncoveredExported(pkg, rel, sf, fileBlocks)...)
+	}
+
+	for _, result := range packages {
+		result.Percent = 100
+		if result.Statements > 0 {
+			result.Percent = float64(result.Covered) * 100 / float64(result.Statements)
+		}
+		result.Passed = float64(result.Covered)*100 >= result.Threshold*float64(result.Statements)
+		report.Packages = append(report.Packages, *result)
+	}
+	sort.Slice(report.Packages, func(i, j int) bool { return report.Packages[i].Path < report.Packages[j].Path })
+	return report, nil
+}
+
+// group splits the blocks of the module's files by module-relative file name, and returns the names sorted.
+func (p *Policy) group(blocks []Block) ([]string, map[string][]Block) {
+	byFile := map[string][]Block{}
+	var files []string
+	for _, b := range blocks {
+		rel, ok := p.module.Rel(b.File)
+		if !ok {
+			continue
+		}
+		if _, seen := byFile[rel]; !seen {
+			files = append(files, rel)
+		}
+		byFile[rel] = append(byFile[rel], b)
+	}
+	sort.Strings(files)
+	return files, byFile
+}
+
+func (p *Policy) uncoveredExported(pkg, rel string, sf *sourceFile, blocks []Block) []FuncGap {
+	var gaps []FuncGap
+	for _, fn := range sf.funcs {
+		if !fn.exported {
+			continue
+		}
+		statements, covered := p.count(fn.span, blocks)
+		if statements > 0 && covered == 0 {
+			gaps = append(gaps, FuncGap{Package: pkg, File: rel, Line: fn.line, Name: fn.name, Statements: statements})
+		}
+	}
+	return gaps
+}
+
+// threshold returns the exact package override, else the longest matching "/..." override, else the default.
+func (p *Policy) threshold(pkg string) float64 {
+	if t, ok := p.packages[pkg]; ok {
+		return t
+	}
+	best, bestLen := p.cfg.Threshold, -1
+	for key, t := range p.packages {
+		prefix, ok := strings.CutSuffix(key, "...")
+		if !ok {
+			continue
+		}
+		prefix = strings.TrimSuffix(prefix, "/")
+		matches := prefix == "" || prefix == "." || pkg == prefix || strings.HasPrefix(pkg, prefix+"/")
+		if matches && len(prefix) > bestLen {
+			best, bestLen = t, len(prefix)
+		}
+	}
+	return best
+}
@@ -0,0 +1,154 @@
+package coverage_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/config"
+	"github.com/cristiano-pacheco/ai-rules/internal/coverage"
+	"github.com/cristiano-pacheco/ai-rules/internal/gomod"
+)
+
+// policyModule writes a module with one file per path, each holding an exported function on lines 3 to 5.
+func policyModule(t *testing.T, files ...string) *gomod.Module {
+	t.Helper()
+	dir := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module example.com/m\n\ngo 1.24\n")
+	for _, rel := range files {
+		header := ""
+		if filepath.Base(rel) == "gen.go" {
+			header = "// Code generated by tool. DO NOT EDIT.\n"
+		}
+		write(rel, header+"package p\n\nfunc Do() int {\n\treturn 1\n}\n")
+	}
+	module, err := gomod.NewModule(dir)
+	if err != nil {
+		t.Fatalf("NewModule: %v", err)
+	}
+	return module
+}
+
+func TestPolicy_Evaluate_Thresholds_PicksMostSpecific(t *testing.T) {
+	// Arrange
+	module := policyModule(t, "a/a.go", "a/b/b.go", "a/b/c/c.go", "d/d.go", "e/e.go")
+	cfg := config.Coverage{
+		Threshold: 80,
+		Packages: map[string]float64{
+			"a/...":               50,
+			"a/b/...":             60,
+			"example.com/m/a/b/c": 70,
+			"e":                   0,
+		},
+	}
+	policy, err := coverage.NewPolicy(cfg, module)
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+	var blocks []coverage.Block
+	for _, rel := range []string{"a/a.go", "a/b/b.go", "a/b/c/c.go", "d/d.go", "e/e.go"} {
+		blocks = append(blocks, coverage.Block{
+			File: module.Path + "/" + rel, StartLine: 3, StartCol: 16, EndLine: 5, EndCol: 2, NumStmt: 1,
+		})
+	}
+
+	// Act
+	report, err := policy.Evaluate(blocks)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	got := map[string]float64{}
+	for _, pkg := range report.Packages {
+		got[pkg.Path] = pkg.Threshold
+	}
+	want := map[string]float64{"a": 50, "a/b": 60, "a/b/c": 70, "d": 80, "e": 0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("thresholds = %v, want %v", got, want)
+	}
+}
+
+func TestPolicy_Evaluate_MixedFiles_CountsOnlyModuleSources(t *testing.T) {
+	// Arrange
+	module := policyModule(t, "p/covered.go", "p/uncovered.go", "p/gen.go", "test/helper.go")
+	cfg := config.Coverage{Threshold: 50, Exclude: []string{"test/**"}, FailOnUncoveredExported: true}
+	policy, err := coverage.NewPolicy(cfg, module)
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+	block := func(file string, count int) coverage.Block {
+		return coverage.Block{File: file, StartLine: 3, StartCol: 16, EndLine: 5, EndCol: 2, NumStmt: 1, Count: count}
+	}
+	blocks := []coverage.Block{
+		block(module.Path+"/p/covered.go", 1),
+		block(module.Path+"/p/uncovered.go", 0),
+		block(module.Path+"/p/gen.go", 0),
+		block(module.Path+"/test/helper.go", 0),
+		block("example.com/other/x.go", 0),
+	}
+
+	// Act
+	report, err := policy.Evaluate(blocks)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	wantPackages := []coverage.PackageResult{
+		{Path: "p", Statements: 2, Covered: 1, Percent: 50, Threshold: 50, Passed: true},
+	}
+	if !reflect.DeepEqual(report.Packages, wantPackages) {
+		t.Errorf("packages = %+v, want %+v", report.Packages, wantPackages)
+	}
+	wantUncovered := []coverage.FuncGap{{Package: "p", File: "p/uncovered.go", Line: 3, Name: "Do", Statements: 1}}
+	if !reflect.DeepEqual(report.Uncovered, wantUncovered) {
+		t.Errorf("uncovered = %+v, want %+v", report.Uncovered, wantUncovered)
+	}
+	if !reflect.DeepEqual(report.Generated, []string{"p/gen.go"}) {
+		t.Errorf("generated = %v, want [p/gen.go]", report.Generated)
+	}
+	if !reflect.DeepEqual(report.Excluded, []string{"test/helper.go"}) {
+		t.Errorf("excluded = %v, want [test/helper.go]", report.Excluded)
+	}
+	if report.Passed() {
+		t.Error("Passed() = true, want false with an uncovered exported function")
+	}
+}
+
+func TestPolicy_Evaluate_BelowThreshold_Fails(t *testing.T) {
+	// Arrange
+	module := policyModule(t, "p/p.go")
+	policy, err := coverage.NewPolicy(config.Coverage{Threshold: 80}, module)
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+	blocks := []coverage.Block{
+		{File: module.Path + "/p/p.go", StartLine: 3, StartCol: 16, EndLine: 4, EndCol: 10, NumStmt: 3, Count: 1},
+		{File: module.Path + "/p/p.go", StartLine: 4, StartCol: 10, EndLine: 5, EndCol: 2, NumStmt: 1},
+	}
+
+	// Act
+	report, err := policy.Evaluate(blocks)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	if len(report.Packages) != 1 || report.Packages[0].Percent != 75 || report.Packages[0].Passed {
+		t.Errorf("packages = %+v, want p at 75%% failing", report.Packages)
+	}
+	if report.Passed() {
+		t.Error("Passed() = true, want false")
+	}
+}
@@ -0,0 +1,105 @@
+// Package coverage evaluates `go test -coverprofile` output against the coverage policy in ai-rules.yaml.
+package coverage
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidProfile is returned when the input is not a Go coverage profile.
+var ErrInvalidProfile = errors.New("invalid coverage profile")
+
+// Block is one basic block of a coverage profile.
+type Block struct {
+	// File is the import-path style name the go tool writes, e.g. github.com/org/repo/pkg/file.go.
+	File      string
+	StartLine int
+	StartCol  int
+	EndLine   int
+	EndCol    int
+	NumStmt   int
+	Count     int
+}
+
+// ParseProfile reads a coverage profile in any mode (set, count, atomic). Blocks reported by several test
+// binaries, as happens with -coverpkg, are merged into one block with the highest count.
+func ParseProfile(r io.Reader) ([]Block, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("read coverage profile: %w", err)
+		}
+		return nil, fmt.Errorf("%w: empty input", ErrInvalidProfile)
+	}
+	if !strings.HasPrefix(scanner.Text(), "mode: ") {
+		return nil, fmt.Errorf("%w: missing mode line", ErrInvalidProfile)
+	}
+
+	type blockKey struct {
+		file                                 string
+		startLine, startCol, endLine, endCol int
+	}
+	merged := map[blockKey]*Block{}
+	lineNo := 1
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		// name.go:line.column,line.column numberOfStatements count
+		colon := strings.LastIndexByte(line, ':')
+		if colon < 0 {
+			return nil, fmt.Errorf("%w: line %d: missing file separator", ErrInvalidProfile, lineNo)
+		}
+		fields := strings.Fields(line[colon+1:])
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%w: line %d: expected range, statements, and count", ErrInvalidProfile, lineNo)
+		}
+		var b Block
+		b.File = line[:colon]
+		if _, err := fmt.Sscanf(fields[0], "%d.%d,%d.%d", &b.StartLine, &b.StartCol, &b.EndLine, &b.EndCol); err != nil {
+			return nil, fmt.Errorf("%w: line %d: bad range %q", ErrInvalidProfile, lineNo, fields[0])
+		}
+		var err error
+		if b.NumStmt, err = strconv.Atoi(fields[1]); err != nil {
+			return nil, fmt.Errorf("%w: line %d: bad statement count %q", ErrInvalidProfile, lineNo, fields[1])
+		}
+		if b.Count, err = strconv.Atoi(fields[2]); err != nil {
+			return nil, fmt.Errorf("%w: line %d: bad hit count %q", ErrInvalidProfile, lineNo, fields[2])
+		}
+
+		key := blockKey{b.File, b.StartLine, b.StartCol, b.EndLine, b.EndCol}
+		if existing, ok := merged[key]; ok {
+			existing.Count = max(existing.Count, b.Count)
+			continue
+		}
+		merged[key] = &b
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read coverage profile: %w", err)
+	}
+
+	blocks := make([]Block, 0, len(merged))
+	for _, b := range merged {
+		blocks = append(blocks, *b)
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		a, b := blocks[i], blocks[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.StartLine != b.StartLine {
+			return a.StartLine < b.StartLine
+		}
+		return a.StartCol < b.StartCol
+	})
+	return blocks, nil
+}
@@ -0,0 +1,96 @@
+package coverage_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/coverage"
+)
+
+func TestParseProfile_ValidProfile_ReturnsSortedBlocks(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		want    []coverage.Block
+	}{
+		{
+			name:    "mode line only",
+			profile: "mode: set\n",
+			want:    []coverage.Block{},
+		},
+		{
+			name: "sorted by file, line, and column",
+			profile: "mode: count\n" +
+				"example.com/m/b.go:3.2,4.3 1 0\n" +
+				"example.com/m/a.go:10.1,12.2 2 5\n" +
+				"\n" +
+				"example.com/m/a.go:1.5,2.2 1 1\n" +
+				"example.com/m/a.go:1.1,2.2 1 1\n",
+			want: []coverage.Block{
+				{File: "example.com/m/a.go", StartLine: 1, StartCol: 1, EndLine: 2, EndCol: 2, NumStmt: 1, Count: 1},
+				{File: "example.com/m/a.go", StartLine: 1, StartCol: 5, EndLine: 2, EndCol: 2, NumStmt: 1, Count: 1},
+				{File: "example.com/m/a.go", StartLine: 10, StartCol: 1, EndLine: 12, EndCol: 2, NumStmt: 2, Count: 5},
+				{File: "example.com/m/b.go", StartLine: 3, StartCol: 2, EndLine: 4, EndCol: 3, NumStmt: 1, Count: 0},
+			},
+		},
+		{
+			name: "blocks from several binaries merge with the highest count",
+			profile: "mode: atomic\n" +
+				"example.com/m/a.go:1.1,2.2 1 0\n" +
+				"example.com/m/a.go:1.1,2.2 1 3\n" +
+				"example.com/m/a.go:1.1,2.2 1 1\n",
+			want: []coverage.Block{
+				{File: "example.com/m/a.go", StartLine: 1, StartCol: 1, EndLine: 2, EndCol: 2, NumStmt: 1, Count: 3},
+			},
+		},
+		{
+			name:    "file name containing a colon",
+			profile: "mode: set\nC:/m/a.go:1.1,2.2 1 1\n",
+			want: []coverage.Block{
+				{File: "C:/m/a.go", StartLine: 1, StartCol: 1, EndLine: 2, EndCol: 2, NumStmt: 1, Count: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, err := coverage.ParseProfile(strings.NewReader(tt.profile))
+
+			// Assert
+			if err != nil {
+				t.Fatalf("ParseProfile: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseProfile_MalformedInput_ReturnsInvalidProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+	}{
+		{name: "empty input", profile: ""},
+		{name: "missing mode line", profile: "example.com/m/a.go:1.1,2.2 1 1\n"},
+		{name: "missing file separator", profile: "mode: set\nexample.com/m/a.go 1 1\n"},
+		{name: "missing count", profile: "mode: set\nexample.com/m/a.go:1.1,2.2 1\n"},
+		{name: "bad range", profile: "mode: set\nexample.com/m/a.go:1,2 1 1\n"},
+		{name: "bad statement count", profile: "mode: set\nexample.com/m/a.go:1.1,2.2 x 1\n"},
+		{name: "bad hit count", profile: "mode: set\nexample.com/m/a.go:1.1,2.2 1 x\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := coverage.ParseProfile(strings.NewReader(tt.profile))
+
+			// Assert
+			if !errors.Is(err, coverage.ErrInvalidProfile) {
+				t.Errorf("error = %v, want %v", err, coverage.ErrInvalidProfile)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package coverage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Report is the outcome of evaluating a profile against the policy.
+type Report struct {
+	Module                  string          `json:"module"`
+	Packages                []PackageResult `json:"packages"`
+	Uncovered               []FuncGap       `json:"uncovered_exported"`
+	Generated               []string        `json:"generated_files"`
+	Excluded                []string        `json:"excluded_files"`
+	FailOnUncoveredExported bool            `json:"fail_on_uncovered_exported"`
+}
+
+// PackageResult is the statement coverage of one package.
+type PackageResult struct {
+	// Path is the module-relative package directory.
+	Path       string  `json:"path"`
+	Statements int     `json:"statements"`
+	Covered    int     `json:"covered"`
+	Percent    float64 `json:"percent"`
+	Threshold  float64 `json:"threshold"`
+	Passed     bool    `json:"passed"`
+}
+
+// FuncGap is an exported function or method with no covered statement.
+type FuncGap struct {
+	Package    string `json:"package"`
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+	Name       string `json:"name"`
+	Statements int    `json:"statements"`
+}
+
+// Passed reports whether every package meets its threshold and, when configured, no exported function is
+// left uncovered.
+func (r *Report) Passed() bool {
+	for _, pkg := range r.Packages {
+		if !pkg.Passed {
+			return false
+		}
+	}
+	return !r.FailOnUncoveredExported || len(r.Uncovered) == 0
+}
+
+// WriteText writes a human-readable table followed by the uncovered exported functions.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PACKAGE\tSTATEMENTS\tCOVERAGE\tTHRESHOLD\tSTATUS")
+	failed := 0
+	for _, pkg := range r.Packages {
+		status := "ok"
+		if !pkg.Passed {
+			status = "FAIL"
+			failed++
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%.1f%%\t%.1f%%\t%s\n", pkg.Path, pkg.Statements, pkg.Percent, pkg.Threshold, status)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+
+	if len(r.Uncovered) > 0 {
+		label := "warning"
+		if r.FailOnUncoveredExported {
+			label = "FAIL"
+		}
+		fmt.Fprintf(w, "\nUncovered exported functions (%s):\n", label)
+		for _, gap := range r.Uncovered {
+			fmt.Fprintf(w, "  %s:%d\t%s\n", gap.File, gap.Line, gap.Name)
+		}
+	}
+	if len(r.Generated) > 0 || len(r.Excluded) > 0 {
+		fmt.Fprintf(w, "\nSkipped %d generated and %d excluded files.\n", len(r.Generated), len(r.Excluded))
+	}
+
+	var summary string
+	switch {
+	case r.Passed():
+		summary = fmt.Sprintf("\ncoverage policy: all %d packages meet their threshold", len(r.Packages))
+	case failed > 0:
+		summary = fmt.Sprintf("\ncoverage policy: %d of %d packages below threshold", failed, len(r.Packages))
+	default:
+		summary = fmt.Sprintf("\ncoverage policy: %d exported functions without coverage", len(r.Uncovered))
+	}
+	if _, err := fmt.Fprintln(w, summary); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+	return nil
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return fmt.Errorf("encode report: %w", err)
+	}
+	return nil
+}
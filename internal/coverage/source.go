@@ -0,0 +1,178 @@
+package coverage
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/gomod"
+)
+
+// sourceFile is what the policy needs to know about one profiled file.
+type sourceFile struct {
+	generated bool
+	funcs     []funcSpan
+	branches  []branchSpan
+}
+
+// span is the extent of a block, from its opening to its closing brace.
+type span struct {
+	start token.Position
+	end   token.Position
+}
+
+// contains reports whether a profile block lies inside the span.
+func (s span) contains(b Block) bool {
+	afterStart := b.StartLine > s.start.Line || (b.StartLine == s.start.Line && b.StartCol >= s.start.Column)
+	beforeEnd := b.EndLine < s.end.Line || (b.EndLine == s.end.Line && b.EndCol <= s.end.Column+1)
+	return afterStart && beforeEnd
+}
+
+// funcSpan is a function or method declaration and the extent of its body.
+type funcSpan struct {
+	span
+	// name is "Func" for functions and "Type.Method" for methods.
+	name     string
+	line     int
+	exported bool
+}
+
+// branchSpan is an if or else block that returns a non-nil error from the enclosing function.
+type branchSpan struct {
+	span
+	// fn is the name of the enclosing funcSpan.
+	fn   string
+	line int
+	// cause is true when the branch handles an error from a call (if err != nil), false when it rejects
+	// input or state by returning an error of its own.
+	cause bool
+}
+
+// sourceIndex parses profiled files on demand and caches the result.
+type sourceIndex struct {
+	module *gomod.Module
+	fset   *token.FileSet
+	files  map[string]*sourceFile
+}
+
+func newSourceIndex(module *gomod.Module) *sourceIndex {
+	return &sourceIndex{module: module, fset: token.NewFileSet(), files: map[string]*sourceFile{}}
+}
+
+// file returns the parsed form of a module-relative file.
+func (s *sourceIndex) file(rel string) (*sourceFile, error) {
+	if sf, ok := s.files[rel]; ok {
+		return sf, nil
+	}
+	f, err := parser.ParseFile(s.fset, s.module.Abs(rel), nil, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s (is the profile from this source tree?): %w", rel, err)
+	}
+
+	sf := &sourceFile{generated: ast.IsGenerated(f)}
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		fnSpan := funcSpan{
+			span:     span{start: s.fset.Position(fn.Body.Lbrace), end: s.fset.Position(fn.Body.Rbrace)},
+			name:     fn.Name.Name,
+			line:     s.fset.Position(fn.Pos()).Line,
+			exported: fn.Name.IsExported(),
+		}
+		if fn.Recv != nil && len(fn.Recv.List) == 1 {
+			recv := s.receiverName(fn.Recv.List[0].Type)
+			fnSpan.name = recv + "." + fn.Name.Name
+			fnSpan.exported = fnSpan.exported && ast.IsExported(recv)
+		}
+		sf.funcs = append(sf.funcs, fnSpan)
+		if s.returnsError(fn.Type) {
+			sf.branches = append(sf.branches, s.errorBranches(fnSpan.name, fn.Body)...)
+		}
+	}
+	s.files[rel] = sf
+	return sf, nil
+}
+
+// receiverName returns the base type name of a receiver expression: T, *T, T[P], or *T[P].
+func (s *sourceIndex) receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return s.receiverName(t.X)
+	case *ast.IndexExpr:
+		return s.receiverName(t.X)
+	case *ast.IndexListExpr:
+		return s.receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	default:
+		return ""
+	}
+}
+
+// returnsError reports whether the last result of a function type is error.
+func (s *sourceIndex) returnsError(ft *ast.FuncType) bool {
+	if ft.Results == nil || len(ft.Results.List) == 0 {
+		return false
+	}
+	last, ok := ft.Results.List[len(ft.Results.List)-1].Type.(*ast.Ident)
+	return ok && last.Name == "error"
+}
+
+// errorBranches finds the if and else blocks of body that return a non-nil error. Function literals are not
+// entered: their returns belong to the closure, not to fn.
+func (s *sourceIndex) errorBranches(fn string, body *ast.BlockStmt) []branchSpan {
+	var branches []branchSpan
+	ast.Inspect(body, func(n ast.Node) bool {
+		ifStmt, ok := n.(*ast.IfStmt)
+		if !ok {
+			_, isClosure := n.(*ast.FuncLit)
+			return !isClosure
+		}
+		blocks := []*ast.BlockStmt{ifStmt.Body}
+		if elseBlock, ok := ifStmt.Else.(*ast.BlockStmt); ok {
+			blocks = append(blocks, elseBlock)
+		}
+		for i, block := range blocks {
+			if !s.returnsNonNil(block) {
+				continue
+			}
+			branches = append(branches, branchSpan{
+				span:  span{start: s.fset.Position(block.Lbrace), end: s.fset.Position(block.Rbrace)},
+				fn:    fn,
+				line:  s.fset.Position(block.Lbrace).Line,
+				cause: i == 0 && s.checksErr(ifStmt.Cond),
+			})
+		}
+		return true
+	})
+	return branches
+}
+
+// returnsNonNil reports whether a statement of block returns a last result other than nil.
+func (s *sourceIndex) returnsNonNil(block *ast.BlockStmt) bool {
+	for _, stmt := range block.List {
+		ret, ok := stmt.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) == 0 {
+			continue
+		}
+		if last, ok := ret.Results[len(ret.Results)-1].(*ast.Ident); !ok || last.Name != "nil" {
+			return true
+		}
+	}
+	return false
+}
+
+// checksErr reports whether cond has the form err != nil, for any variable named err or ending in Err.
+func (s *sourceIndex) checksErr(cond ast.Expr) bool {
+	bin, ok := cond.(*ast.BinaryExpr)
+	if !ok || bin.Op != token.NEQ {
+		return false
+	}
+	x, ok := bin.X.(*ast.Ident)
+	y, isIdent := bin.Y.(*ast.Ident)
+	return ok && isIdent && y.Name == "nil" && (x.Name == "err" || strings.HasSuffix(x.Name, "Err"))
+}
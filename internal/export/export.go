@@ -0,0 +1,132 @@
+// Package export installs the embedded skills into a project directory, keeping only the go-unit-tests
+// variant for the project's unit test flavor.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/config"
+)
+
+// skillFile is the file that marks a directory as a skill.
+const skillFile = "SKILL.md"
+
+// ErrUnknownFlavor is returned for a unit test flavor without a go-unit-tests variant.
+var ErrUnknownFlavor = errors.New("unknown unit test flavor")
+
+// flavorSkills maps each unit test flavor to the skill that documents it. Exactly one of them is installed.
+var flavorSkills = map[string]string{
+	config.FlavorTestify: "go-unit-tests",
+	config.FlavorGomock:  "go-unit-tests-gomock",
+	config.FlavorStdlib:  "go-unit-tests-stdlib",
+	config.FlavorGinkgo:  "go-unit-tests-ginkgo",
+}
+
+// Result lists what Export changed, by skill name.
+type Result struct {
+	Installed []string
+	// Removed holds variants of other flavors found in the destination and deleted, so a project that
+	// switches flavor does not keep both.
+	Removed []string
+}
+
+// Exporter copies skills from an fs.FS whose root holds one directory per skill.
+type Exporter struct {
+	skills fs.FS
+	flavor string
+}
+
+// NewExporter returns an exporter of skills that installs the go-unit-tests variant of the unit test flavor.
+func NewExporter(skills fs.FS, flavor string) *Exporter {
+	return &Exporter{skills: skills, flavor: flavor}
+}
+
+// Skills returns the sorted names of the skills Export installs.
+func (e *Exporter) Skills() ([]string, error) {
+	selected, ok := flavorSkills[e.flavor]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFlavor, e.flavor)
+	}
+	entries, err := fs.ReadDir(e.skills, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read skills: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() || (e.isFlavorSkill(entry.Name()) && entry.Name() != selected) {
+			continue
+		}
+		if _, err := fs.Stat(e.skills, entry.Name()+"/"+skillFile); err != nil {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// Export writes every selected skill to dir/<name>, replacing an existing copy, and deletes the variants of
+// other flavors from dir. Directories in dir that are not skills of this repository are left untouched.
+func (e *Exporter) Export(dir string) (*Result, error) {
+	names, err := e.Skills()
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{}
+	for _, name := range names {
+		if err := e.install(name, filepath.Join(dir, name)); err != nil {
+			return nil, err
+		}
+		result.Installed = append(result.Installed, name)
+	}
+	for _, name := range e.otherFlavorSkills() {
+		target := filepath.Join(dir, name)
+		if _, err := os.Stat(target); err != nil {
+			continue
+		}
+		if err := os.RemoveAll(target); err != nil {
+			return nil, fmt.Errorf("remove %s: %w", name, err)
+		}
+		result.Removed = append(result.Removed, name)
+	}
+	return result, nil
+}
+
+func (e *Exporter) install(name, target string) error {
+	if err := os.RemoveAll(target); err != nil {
+		return fmt.Errorf("replace %s: %w", name, err)
+	}
+	sub, err := fs.Sub(e.skills, name)
+	if err != nil {
+		return err
+	}
+	if err := os.CopyFS(target, sub); err != nil {
+		return fmt.Errorf("install %s: %w", name, err)
+	}
+	return nil
+}
+
+func (e *Exporter) isFlavorSkill(name string) bool {
+	for _, skill := range flavorSkills {
+		if skill == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Exporter) otherFlavorSkills() []string {
+	var names []string
+	for flavor, skill := range flavorSkills {
+		if flavor != e.flavor {
+			names = append(names, skill)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
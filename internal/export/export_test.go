@@ -0,0 +1,110 @@
+package export_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/config"
+	"github.com/cristiano-pacheco/ai-rules/internal/export"
+)
+
+// skills is one shared skill, the four go-unit-tests variants, and a directory that is not a skill.
+var skills = fstest.MapFS{
+	"go-service/SKILL.md":                   {Data: []byte("service")},
+	"go-service/examples/basic.md":          {Data: []byte("example")},
+	"go-unit-tests/SKILL.md":                {Data: []byte("testify")},
+	"go-unit-tests-gomock/SKILL.md":         {Data: []byte("gomock")},
+	"go-unit-tests-stdlib/SKILL.md":         {Data: []byte("stdlib")},
+	"go-unit-tests-ginkgo/SKILL.md":         {Data: []byte("ginkgo")},
+	"drafts/notes.md":                       {Data: []byte("not a skill")},
+	"go-unit-tests-stdlib/examples/stub.md": {Data: []byte("stub")},
+}
+
+func TestExporter_Skills_Flavors_SelectOneVariant(t *testing.T) {
+	tests := []struct {
+		flavor string
+		want   []string
+	}{
+		{flavor: config.FlavorTestify, want: []string{"go-service", "go-unit-tests"}},
+		{flavor: config.FlavorGomock, want: []string{"go-service", "go-unit-tests-gomock"}},
+		{flavor: config.FlavorStdlib, want: []string{"go-service", "go-unit-tests-stdlib"}},
+		{flavor: config.FlavorGinkgo, want: []string{"go-service", "go-unit-tests-ginkgo"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.flavor, func(t *testing.T) {
+			// Arrange
+			exporter := export.NewExporter(skills, tt.flavor)
+
+			// Act
+			got, err := exporter.Skills()
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Skills: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExporter_Skills_UnknownFlavor_ReturnsError(t *testing.T) {
+	// Arrange
+	exporter := export.NewExporter(skills, "jest")
+
+	// Act
+	_, err := exporter.Skills()
+
+	// Assert
+	if !errors.Is(err, export.ErrUnknownFlavor) {
+		t.Errorf("error = %v, want %v", err, export.ErrUnknownFlavor)
+	}
+}
+
+func TestExporter_Export_SwitchedFlavor_ReplacesVariant(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	for _, rel := range []string{"go-unit-tests/SKILL.md", "go-service/stale.md", "own-skill/SKILL.md"} {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exporter := export.NewExporter(skills, config.FlavorStdlib)
+
+	// Act
+	result, err := exporter.Export(dir)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	want := &export.Result{
+		Installed: []string{"go-service", "go-unit-tests-stdlib"},
+		Removed:   []string{"go-unit-tests"},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("result = %+v, want %+v", result, want)
+	}
+	for rel, exists := range map[string]bool{
+		"go-unit-tests-stdlib/examples/stub.md": true,
+		"go-service/examples/basic.md":          true,
+		"own-skill/SKILL.md":                    true,
+		"go-service/stale.md":                   false,
+		"go-unit-tests":                         false,
+		"drafts":                                false,
+	} {
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(rel)))
+		if got := err == nil; got != exists {
+			t.Errorf("%s exists = %v, want %v", rel, got, exists)
+		}
+	}
+}
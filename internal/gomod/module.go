@@ -0,0 +1,83 @@
+// Package gomod reads the go.mod of a module and maps import paths and profile file names onto its tree.
+package gomod
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrNoModulePath is returned when go.mod has no module directive.
+var ErrNoModulePath = errors.New("go.mod has no module directive")
+
+// Module maps import paths, and the import-path style file names of a coverage profile, onto the module's
+// source tree.
+type Module struct {
+	// Path is the module path from go.mod.
+	Path string
+	// Dir is the directory containing go.mod.
+	Dir string
+}
+
+// NewModule reads the module path from dir/go.mod.
+func NewModule(dir string) (*Module, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve module dir: %w", err)
+	}
+	m := &Module{Dir: abs}
+	if m.Path, err = m.readPath(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Rel returns the module-relative, slash-separated form of an import path or profile file name: "." for the
+// module path itself, and false when it belongs to another module.
+func (m *Module) Rel(name string) (string, bool) {
+	if name == m.Path {
+		return ".", true
+	}
+	rel, ok := strings.CutPrefix(name, m.Path+"/")
+	return rel, ok
+}
+
+// Abs returns the path on disk of a module-relative file.
+func (m *Module) Abs(rel string) string {
+	return filepath.Join(m.Dir, filepath.FromSlash(rel))
+}
+
+func (m *Module) readPath() (string, error) {
+	f, err := os.Open(filepath.Join(m.Dir, "go.mod"))
+	if err != nil {
+		return "", fmt.Errorf("open go.mod: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		rest, ok := strings.CutPrefix(line, "module")
+		if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+			continue
+		}
+		path := strings.TrimSpace(rest)
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			path = unquoted
+		}
+		if path != "" {
+			return path, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("read go.mod: %w", err)
+	}
+	return "", ErrNoModulePath
+}
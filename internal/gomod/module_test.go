@@ -0,0 +1,72 @@
+package gomod_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/gomod"
+)
+
+func TestModule_Rel_Names_ReturnsModuleRelative(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{name: "module root", in: "example.com/m", want: ".", wantOK: true},
+		{name: "package", in: "example.com/m/p", want: "p", wantOK: true},
+		{name: "profile file", in: "example.com/m/p/p.go", want: "p/p.go", wantOK: true},
+		{name: "longer module path", in: "example.com/mod/p"},
+		{name: "other module", in: "example.com/other/p"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			m := &gomod.Module{Path: "example.com/m", Dir: t.TempDir()}
+
+			// Act
+			got, ok := m.Rel(tt.in)
+
+			// Assert
+			if ok != tt.wantOK || ok && got != tt.want {
+				t.Errorf("Rel(%q) = %q, %t; want %q, %t", tt.in, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNewModule_GoMod_ReadsPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		goMod   string
+		want    string
+		wantErr error
+	}{
+		{name: "plain", goMod: "module example.com/m\n\ngo 1.24\n", want: "example.com/m"},
+		{name: "quoted with comment", goMod: "// the module\nmodule \"example.com/m\" // main\n", want: "example.com/m"},
+		{name: "no module directive", goMod: "go 1.24\n", wantErr: gomod.ErrNoModulePath},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(tt.goMod), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			m, err := gomod.NewModule(dir)
+
+			// Assert
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && m.Path != tt.want {
+				t.Errorf("Path = %q, want %q", m.Path, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,364 @@
+package mockery
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConfigFileName is the mockery configuration file looked up in the module root.
+const ConfigFileName = ".mockery.yaml"
+
+// ErrUnsupportedConfig is returned for .mockery.yaml layouts the in-place editor cannot change safely.
+var ErrUnsupportedConfig = errors.New("unsupported .mockery.yaml layout")
+
+// defaultConfig is the file written for a module without a .mockery.yaml: one mocks package in test/mocks
+// with the Mock<Interface> names the go-unit-tests skill uses, in the keys of mockery v2.
+const defaultConfig = `# Interfaces with generated mocks. "ai-rules mocks" adds the ones constructors depend on.
+dir: test/mocks
+outpkg: mocks
+mockname: "Mock{{.InterfaceName}}"
+filename: "mock_{{.InterfaceName | snakecase}}.go"
+packages:
+`
+
+// defaultConfigV3 is defaultConfig in the keys of mockery v3, which renamed outpkg to pkgname and mockname to
+// structname, and selects the testify mocks with a template.
+const defaultConfigV3 = `# Interfaces with generated mocks. "ai-rules mocks" adds the ones constructors depend on.
+template: testify
+dir: test/mocks
+pkgname: mocks
+structname: "Mock{{.InterfaceName}}"
+filename: "mock_{{.InterfaceName | snakecase}}.go"
+packages:
+`
+
+// Config is a .mockery.yaml document. It is edited line by line, so comments, key order, and per-interface
+// settings survive every change. Only the keys that select interfaces are interpreted: dir, all, and
+// packages.<path>.interfaces.<name>, with all: true also read from packages.<path>.config.
+type Config struct {
+	lines []string
+	root  *configNode
+}
+
+// configNode is one "key:" line and the lines nested below it.
+type configNode struct {
+	key    string
+	value  string
+	indent int
+	// line is the index of the key line and end the index after the last line of the subtree.
+	line     int
+	end      int
+	children []*configNode
+}
+
+func (n *configNode) child(key string) *configNode {
+	for _, c := range n.children {
+		if c.key == key {
+			return c
+		}
+	}
+	return nil
+}
+
+// NewConfig returns the configuration written for a module that has none, for the major version of
+// mockery that generates its mocks. A version other than 3, including 0 for unknown, gets the v2 keys.
+func NewConfig(major int) *Config {
+	doc := defaultConfig
+	if major == 3 {
+		doc = defaultConfigV3
+	}
+	c, _ := ParseConfig([]byte(doc))
+	return c
+}
+
+// ParseConfig reads a .mockery.yaml document.
+func ParseConfig(data []byte) (*Config, error) {
+	text := strings.TrimSuffix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	c := &Config{}
+	if text != "" {
+		c.lines = strings.Split(text, "\n")
+	}
+	for i, line := range c.lines {
+		if strings.TrimLeft(line, "\t") != line {
+			return nil, fmt.Errorf("%w: line %d: tabs are not allowed for indentation", ErrUnsupportedConfig, i+1)
+		}
+	}
+	c.build()
+	return c, nil
+}
+
+// Dir returns the top-level output directory, or "" when the file does not set one.
+func (c *Config) Dir() string {
+	if dir := c.root.child("dir"); dir != nil {
+		return dir.value
+	}
+	return ""
+}
+
+// WithExpecter reports whether the mocks have the typed EXPECT() API: turned on for every mock in a v2
+// file, always generated by the testify template of v3.
+func (c *Config) WithExpecter() bool {
+	if t := c.root.child("template"); t != nil {
+		return t.value == "testify"
+	}
+	n := c.root.child("with-expecter")
+	return n != nil && n.value == "true"
+}
+
+// Has reports whether mockery generates a mock for the interface, by name or through all: true.
+func (c *Config) Has(i Interface) bool {
+	if all := c.root.child("all"); all != nil && all.value == "true" {
+		return true
+	}
+	pkg := c.pkg(i.Package)
+	if pkg == nil {
+		return false
+	}
+	if cfg := pkg.child("config"); cfg != nil {
+		if all := cfg.child("all"); all != nil && all.value == "true" {
+			return true
+		}
+	}
+	interfaces := pkg.child("interfaces")
+	return interfaces != nil && interfaces.child(i.Name) != nil
+}
+
+// Interfaces returns the interfaces listed by name, sorted.
+func (c *Config) Interfaces() []Interface {
+	var list []Interface
+	if packages := c.root.child("packages"); packages != nil {
+		for _, pkg := range packages.children {
+			if interfaces := pkg.child("interfaces"); interfaces != nil {
+				for _, n := range interfaces.children {
+					list = append(list, Interface{Package: pkg.key, Name: n.key})
+				}
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].String() < list[j].String() })
+	return list
+}
+
+// Add lists the interface under its package, creating the package entry when needed. Entries are inserted in
+// alphabetical position among their siblings. Adding an interface the file already generates is a no-op.
+func (c *Config) Add(i Interface) error {
+	if c.Has(i) {
+		return nil
+	}
+	unit := c.indentUnit()
+	packages := c.root.child("packages")
+	if packages == nil {
+		c.insert(c.root.end, []string{"packages:"})
+		packages = c.root.child("packages")
+	}
+	if err := c.checkBlock(packages); err != nil {
+		return err
+	}
+
+	pkg := c.pkg(i.Package)
+	if pkg == nil {
+		indent := c.childIndent(packages, unit)
+		c.insert(c.position(packages, i.Package), []string{
+			strings.Repeat(" ", indent) + i.Package + ":",
+			strings.Repeat(" ", indent+unit) + "interfaces:",
+			strings.Repeat(" ", indent+2*unit) + i.Name + ":",
+		})
+		return nil
+	}
+	if err := c.checkBlock(pkg); err != nil {
+		return err
+	}
+
+	interfaces := pkg.child("interfaces")
+	if interfaces == nil {
+		indent := c.childIndent(pkg, unit)
+		c.insert(pkg.end, []string{
+			strings.Repeat(" ", indent) + "interfaces:",
+			strings.Repeat(" ", indent+unit) + i.Name + ":",
+		})
+		return nil
+	}
+	if err := c.checkBlock(interfaces); err != nil {
+		return err
+	}
+	indent := c.childIndent(interfaces, unit)
+	c.insert(c.position(interfaces, i.Name), []string{strings.Repeat(" ", indent) + i.Name + ":"})
+	return nil
+}
+
+// Remove deletes the interface entry and its settings, then the interfaces and package entries when they are
+// left empty. It reports whether the interface was listed.
+func (c *Config) Remove(i Interface) bool {
+	pkg := c.pkg(i.Package)
+	if pkg == nil || pkg.child("interfaces") == nil || pkg.child("interfaces").child(i.Name) == nil {
+		return false
+	}
+	n := pkg.child("interfaces").child(i.Name)
+	c.delete(n.line, n.end)
+
+	pkg = c.pkg(i.Package)
+	if interfaces := pkg.child("interfaces"); len(interfaces.children) == 0 && interfaces.value == "" {
+		c.delete(interfaces.line, interfaces.end)
+	}
+	if pkg = c.pkg(i.Package); len(pkg.children) == 0 && pkg.value == "" {
+		c.delete(pkg.line, pkg.end)
+	}
+	return true
+}
+
+// Bytes returns the document with a trailing newline.
+func (c *Config) Bytes() []byte {
+	if len(c.lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(c.lines, "\n") + "\n")
+}
+
+func (c *Config) pkg(path string) *configNode {
+	if packages := c.root.child("packages"); packages != nil {
+		return packages.child(path)
+	}
+	return nil
+}
+
+// checkBlock rejects nodes whose value is written inline, such as "interfaces: {}", since children cannot be
+// added below them.
+func (c *Config) checkBlock(n *configNode) error {
+	if n.value == "" {
+		return nil
+	}
+	return fmt.Errorf("%w: line %d: %q has an inline value; write it as a block mapping",
+		ErrUnsupportedConfig, n.line+1, n.key)
+}
+
+// position returns the line a new child key of parent is inserted at: before the first sibling that sorts
+// after it, and above the comments directly preceding that sibling.
+func (c *Config) position(parent *configNode, key string) int {
+	for _, sibling := range parent.children {
+		if sibling.key <= key {
+			continue
+		}
+		at := sibling.line
+		for at > parent.line+1 && strings.HasPrefix(strings.TrimSpace(c.lines[at-1]), "#") {
+			at--
+		}
+		return at
+	}
+	return parent.end
+}
+
+func (c *Config) childIndent(parent *configNode, unit int) int {
+	if len(parent.children) > 0 {
+		return parent.children[0].indent
+	}
+	return parent.indent + unit
+}
+
+// indentUnit returns the indentation step the file uses, two spaces when it has no nested keys yet.
+func (c *Config) indentUnit() int {
+	var walk func(n *configNode) int
+	walk = func(n *configNode) int {
+		for _, child := range n.children {
+			if n.line >= 0 && child.indent > n.indent {
+				return child.indent - n.indent
+			}
+			if unit := walk(child); unit > 0 {
+				return unit
+			}
+		}
+		return 0
+	}
+	if unit := walk(c.root); unit > 0 {
+		return unit
+	}
+	return 2
+}
+
+func (c *Config) insert(at int, lines []string) {
+	c.lines = append(c.lines[:at], append(lines, c.lines[at:]...)...)
+	c.build()
+}
+
+func (c *Config) delete(from, to int) {
+	c.lines = append(c.lines[:from], c.lines[to:]...)
+	c.build()
+}
+
+// build indexes the key lines by indentation. Sequence items and scalar continuation lines extend the
+// subtree of the key above them without becoming keys.
+func (c *Config) build() {
+	c.root = &configNode{indent: -1, line: -1}
+	stack := []*configNode{c.root}
+	for i, line := range c.lines {
+		text := strings.TrimLeft(line, " ")
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		indent := len(line) - len(text)
+		for len(stack) > 1 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		if key, value, ok := c.splitKey(text); ok && text != "-" && !strings.HasPrefix(text, "- ") {
+			n := &configNode{key: key, value: value, indent: indent, line: i}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, n)
+			stack = append(stack, n)
+		}
+		for _, n := range stack {
+			n.end = i + 1
+		}
+	}
+}
+
+// splitKey splits "key: value # comment" into its unquoted key and value.
+func (c *Config) splitKey(text string) (key, value string, ok bool) {
+	var rest string
+	if text[0] == '"' || text[0] == '\'' {
+		end := strings.IndexByte(text[1:], text[0])
+		if end < 0 {
+			return "", "", false
+		}
+		key, rest = c.unquote(text[:end+2]), text[end+2:]
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", false
+		}
+		rest = rest[1:]
+	} else {
+		colon := strings.Index(text, ": ")
+		switch {
+		case colon >= 0:
+			key, rest = text[:colon], text[colon+1:]
+		case strings.HasSuffix(text, ":"):
+			key = strings.TrimSuffix(text, ":")
+		default:
+			return "", "", false
+		}
+	}
+	rest = strings.TrimSpace(rest)
+	switch {
+	case strings.HasPrefix(rest, "#"):
+		rest = ""
+	case rest != "" && (rest[0] == '"' || rest[0] == '\''):
+		if end := strings.IndexByte(rest[1:], rest[0]); end >= 0 {
+			rest = rest[:end+2]
+		}
+	default:
+		if i := strings.Index(rest, " #"); i >= 0 {
+			rest = strings.TrimSpace(rest[:i])
+		}
+	}
+	return key, c.unquote(rest), true
+}
+
+func (c *Config) unquote(s string) string {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil && s[0] == '"' {
+		return unquoted
+	}
+	return s
+}
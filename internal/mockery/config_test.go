@@ -0,0 +1,210 @@
+package mockery_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/mockery"
+)
+
+func TestConfig_Add_Layouts_InsertsInPlace(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		add  mockery.Interface
+		want string
+	}{
+		{
+			name: "new package, sorted among the others",
+			doc: "dir: test/mocks\npackages:\n  example.com/a:\n    interfaces:\n      A:\n" +
+				"  example.com/c:\n    interfaces:\n      C:\n",
+			add: mockery.Interface{Package: "example.com/b", Name: "B"},
+			want: "dir: test/mocks\npackages:\n  example.com/a:\n    interfaces:\n      A:\n" +
+				"  example.com/b:\n    interfaces:\n      B:\n  example.com/c:\n    interfaces:\n      C:\n",
+		},
+		{
+			name: "existing package keeps comments and settings",
+			doc: "packages:\n  example.com/a:\n    interfaces:\n      # the writer\n      Writer:\n" +
+				"        config:\n          mockname: W\n",
+			add: mockery.Interface{Package: "example.com/a", Name: "Reader"},
+			want: "packages:\n  example.com/a:\n    interfaces:\n      Reader:\n      # the writer\n      Writer:\n" +
+				"        config:\n          mockname: W\n",
+		},
+		{
+			name: "package without interfaces",
+			doc:  "packages:\n  example.com/a:\n    config:\n      dir: x\n",
+			add:  mockery.Interface{Package: "example.com/a", Name: "A"},
+			want: "packages:\n  example.com/a:\n    config:\n      dir: x\n    interfaces:\n      A:\n",
+		},
+		{
+			name: "four-space indentation",
+			doc:  "with-expecter: true\nconfig:\n    a: b\n",
+			add:  mockery.Interface{Package: "example.com/a", Name: "A"},
+			want: "with-expecter: true\nconfig:\n    a: b\npackages:\n    example.com/a:\n        interfaces:\n            A:\n",
+		},
+		{
+			name: "already generated through all",
+			doc:  "packages:\n  example.com/a:\n    config:\n      all: true\n",
+			add:  mockery.Interface{Package: "example.com/a", Name: "A"},
+			want: "packages:\n  example.com/a:\n    config:\n      all: true\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cfg, err := mockery.ParseConfig([]byte(tt.doc))
+			if err != nil {
+				t.Fatalf("ParseConfig: %v", err)
+			}
+
+			// Act
+			err = cfg.Add(tt.add)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Add: %v", err)
+			}
+			if got := string(cfg.Bytes()); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Add_InlineValue_ReturnsUnsupported(t *testing.T) {
+	// Arrange
+	cfg, err := mockery.ParseConfig([]byte("packages:\n  example.com/a:\n    interfaces: {}\n"))
+	if err != nil {
+		t.Fatalf("ParseConfig: %v", err)
+	}
+
+	// Act
+	err = cfg.Add(mockery.Interface{Package: "example.com/a", Name: "A"})
+
+	// Assert
+	if !errors.Is(err, mockery.ErrUnsupportedConfig) {
+		t.Errorf("error = %v, want %v", err, mockery.ErrUnsupportedConfig)
+	}
+}
+
+func TestParseConfig_TabIndentation_ReturnsUnsupported(t *testing.T) {
+	// Act
+	_, err := mockery.ParseConfig([]byte("packages:\n\texample.com/a:\n"))
+
+	// Assert
+	if !errors.Is(err, mockery.ErrUnsupportedConfig) {
+		t.Errorf("error = %v, want %v", err, mockery.ErrUnsupportedConfig)
+	}
+}
+
+func TestConfig_Remove_LastInterface_DropsEmptyParents(t *testing.T) {
+	tests := []struct {
+		name   string
+		doc    string
+		remove mockery.Interface
+		want   string
+		found  bool
+	}{
+		{
+			name:   "settings go with the interface",
+			doc:    "packages:\n  example.com/a:\n    interfaces:\n      A:\n        config:\n          x: y\n      B:\n",
+			remove: mockery.Interface{Package: "example.com/a", Name: "A"},
+			want:   "packages:\n  example.com/a:\n    interfaces:\n      B:\n",
+			found:  true,
+		},
+		{
+			name:   "empty package is removed",
+			doc:    "packages:\n  example.com/a:\n    interfaces:\n      A:\n  example.com/b:\n    interfaces:\n      B:\n",
+			remove: mockery.Interface{Package: "example.com/a", Name: "A"},
+			want:   "packages:\n  example.com/b:\n    interfaces:\n      B:\n",
+			found:  true,
+		},
+		{
+			name:   "package with config stays",
+			doc:    "packages:\n  example.com/a:\n    config:\n      dir: x\n    interfaces:\n      A:\n",
+			remove: mockery.Interface{Package: "example.com/a", Name: "A"},
+			want:   "packages:\n  example.com/a:\n    config:\n      dir: x\n",
+			found:  true,
+		},
+		{
+			name:   "not listed",
+			doc:    "packages:\n  example.com/a:\n    interfaces:\n      A:\n",
+			remove: mockery.Interface{Package: "example.com/a", Name: "B"},
+			want:   "packages:\n  example.com/a:\n    interfaces:\n      A:\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cfg, err := mockery.ParseConfig([]byte(tt.doc))
+			if err != nil {
+				t.Fatalf("ParseConfig: %v", err)
+			}
+
+			// Act
+			found := cfg.Remove(tt.remove)
+
+			// Assert
+			if found != tt.found {
+				t.Errorf("Remove = %t, want %t", found, tt.found)
+			}
+			if got := string(cfg.Bytes()); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Interfaces_QuotedKeysAndComments_ListsSorted(t *testing.T) {
+	// Arrange
+	doc := "dir: \"test/mocks\" # output\npackages:\n  'example.com/b':\n    interfaces:\n      B:\n" +
+		"  example.com/a:\n    interfaces:\n      # no mock yet\n      \"A\":\n"
+	cfg, err := mockery.ParseConfig([]byte(doc))
+	if err != nil {
+		t.Fatalf("ParseConfig: %v", err)
+	}
+
+	// Act
+	got := cfg.Interfaces()
+
+	// Assert
+	want := []mockery.Interface{{Package: "example.com/a", Name: "A"}, {Package: "example.com/b", Name: "B"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if dir := cfg.Dir(); dir != "test/mocks" {
+		t.Errorf("Dir = %q, want %q", dir, "test/mocks")
+	}
+}
+
+func TestNewConfig_MajorVersion_UsesItsKeys(t *testing.T) {
+	tests := []struct {
+		name         string
+		major        int
+		wantKey      string
+		wantExpecter bool
+	}{
+		{name: "unknown", major: 0, wantKey: "mockname:"},
+		{name: "v2", major: 2, wantKey: "mockname:"},
+		{name: "v3", major: 3, wantKey: "structname:", wantExpecter: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			cfg := mockery.NewConfig(tt.major)
+
+			// Assert
+			if got := string(cfg.Bytes()); !strings.Contains("\n"+got, "\n"+tt.wantKey) {
+				t.Errorf("config has no %s line:\n%s", tt.wantKey, got)
+			}
+			if got := cfg.WithExpecter(); got != tt.wantExpecter {
+				t.Errorf("WithExpecter = %t, want %t", got, tt.wantExpecter)
+			}
+			if got := cfg.Dir(); got != "test/mocks" {
+				t.Errorf("Dir = %q, want %q", got, "test/mocks")
+			}
+		})
+	}
+}
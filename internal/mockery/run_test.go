@@ -0,0 +1,147 @@
+package mockery_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/mockery"
+)
+
+// mockeryMock is a file mockery generated, written so it can be a shell printf format too.
+const mockeryMock = "// Code generated by mockery v2.53.3. DO NOT EDIT.\n\npackage mocks\n"
+
+// fakeMockery writes a shell script that stands in for mockery: it prints version for "version" and runs
+// script for anything else.
+func fakeMockery(t *testing.T, version, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake mockery is a shell script")
+	}
+	path := filepath.Join(t.TempDir(), "mockery")
+	body := "#!/bin/sh\nif [ \"$1\" = version ]; then\n  echo '" + version + "'\n  exit 0\nfi\n" + script + "\n"
+	if err := os.WriteFile(path, []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunner_Check_Versions_ReturnMajor(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		want    int
+		wantErr error
+	}{
+		{name: "v2", version: "v2.53.3", want: 2},
+		{name: "v3", version: "v3.2.5", want: 3},
+		{name: "unprefixed", version: "mockery version 2.40.1", want: 2},
+		{name: "v1", version: "v1.1.2", wantErr: mockery.ErrUnsupportedMockery},
+		{name: "no version", version: "mockery", wantErr: mockery.ErrUnsupportedMockery},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			runner := mockery.NewRunner(fakeMockery(t, tt.version, "exit 0"), t.TempDir(), ".mockery.yaml")
+
+			// Act
+			got, err := runner.Check()
+
+			// Assert
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("major = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunner_Check_MissingBinary_ReturnsNotFound(t *testing.T) {
+	// Arrange
+	runner := mockery.NewRunner(filepath.Join(t.TempDir(), "mockery"), t.TempDir(), ".mockery.yaml")
+
+	// Act
+	_, err := runner.Check()
+
+	// Assert
+	if !errors.Is(err, mockery.ErrMockeryNotFound) {
+		t.Errorf("error = %v, want %v", err, mockery.ErrMockeryNotFound)
+	}
+}
+
+func TestRunner_Regenerate_MockeryResult_KeepsOrRemovesMocks(t *testing.T) {
+	tests := []struct {
+		name string
+		// script runs from the module directory in place of mockery.
+		script    string
+		wantErr   bool
+		wantFiles []string
+		wantGone  []string
+	}{
+		{
+			name:      "failure keeps every mock",
+			script:    "exit 1",
+			wantErr:   true,
+			wantFiles: []string{"mock_a.go", "mock_stale.go", "helper.go"},
+		},
+		{
+			name:      "success removes the mocks it did not write",
+			script:    "printf '" + mockeryMock + "' > test/mocks/mock_a.go",
+			wantFiles: []string{"mock_a.go", "helper.go"},
+			wantGone:  []string{"mock_stale.go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			mocks := filepath.Join(dir, "test", "mocks")
+			if err := os.MkdirAll(mocks, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			for name, content := range map[string]string{
+				"mock_a.go":     mockeryMock,
+				"mock_stale.go": mockeryMock,
+				"helper.go":     "package mocks\n",
+			} {
+				if err := os.WriteFile(filepath.Join(mocks, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			runner := mockery.NewRunner(fakeMockery(t, "v2.53.3", tt.script), dir, ".mockery.yaml")
+			var stdout, stderr bytes.Buffer
+
+			// Act
+			removed, err := runner.Regenerate("test/mocks", &stdout, &stderr)
+
+			// Assert
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %t", err, tt.wantErr)
+			}
+			for _, name := range tt.wantFiles {
+				if _, err := os.Stat(filepath.Join(mocks, name)); err != nil {
+					t.Errorf("%s: %v", name, err)
+				}
+			}
+			var want []string
+			for _, name := range tt.wantGone {
+				want = append(want, filepath.Join(mocks, name))
+				if _, err := os.Stat(filepath.Join(mocks, name)); !errors.Is(err, os.ErrNotExist) {
+					t.Errorf("%s still exists", name)
+				}
+			}
+			if !reflect.DeepEqual(removed, want) {
+				t.Errorf("removed = %v, want %v", removed, want)
+			}
+			if stash, _ := filepath.Glob(filepath.Join(dir, ".ai-rules-mocks-*")); len(stash) > 0 {
+				t.Errorf("stash left behind: %v", stash)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+// Package mockery keeps a module's .mockery.yaml in step with the interfaces its constructors depend on, so
+// every port a use case takes has a generated mock and no mock outlives its interface.
+//
+// Scanner finds the interface parameters of the module's constructors, Config edits .mockery.yaml in place,
+// and Runner regenerates the mocks with the mockery binary.
+package mockery
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Interface is an exported interface type declared in the module.
+type Interface struct {
+	// Package is the import path of the declaring package.
+	Package string
+	Name    string
+}
+
+func (i Interface) String() string {
+	return i.Package + "." + i.Name
+}
+
+// Dependency is a constructor parameter whose type is an interface of the module.
+type Dependency struct {
+	Interface Interface
+	// Constructor is the package-qualified constructor, such as user.NewUserCreateUseCase.
+	Constructor string
+	// Pos is the module-relative file and line of the parameter.
+	Pos string
+}
+
+// Inventory is what Scan found in the module.
+type Inventory struct {
+	// Module is the module path.
+	Module string
+	// Interfaces holds the exported interface names of every package, keyed by import path.
+	Interfaces map[string]map[string]bool
+	// Dependencies are the interface parameters of every exported New* function, sorted by interface and
+	// then by position.
+	Dependencies []Dependency
+}
+
+// Declares reports whether the module declares the interface.
+func (inv *Inventory) Declares(i Interface) bool {
+	return inv.Interfaces[i.Package][i.Name]
+}
+
+// Contains reports whether an import path belongs to the module.
+func (inv *Inventory) Contains(pkg string) bool {
+	return pkg == inv.Module || strings.HasPrefix(pkg, inv.Module+"/")
+}
+
+// Uses reports whether a constructor depends on the interface.
+func (inv *Inventory) Uses(i Interface) bool {
+	for _, dep := range inv.Dependencies {
+		if dep.Interface == i {
+			return true
+		}
+	}
+	return false
+}
+
+// Scanner walks the packages of one module.
+type Scanner struct {
+	modulePath string
+	dir        string
+	fset       *token.FileSet
+}
+
+// scannedPackage is the parsed non-test files of one importable package.
+type scannedPackage struct {
+	name  string
+	files []*ast.File
+}
+
+// NewScanner returns a scanner for the module at dir whose path is modulePath.
+func NewScanner(modulePath, dir string) *Scanner {
+	return &Scanner{modulePath: modulePath, dir: dir, fset: token.NewFileSet()}
+}
+
+// Scan parses every package of the module and resolves constructor parameters to the interfaces they name.
+// Test files, generated files, main packages, testdata, vendor, hidden directories, and nested modules are
+// skipped. Only interfaces declared in the module are resolved; parameters of standard library or third-party
+// interface types are not reported.
+func (s *Scanner) Scan() (*Inventory, error) {
+	pkgs, err := s.parse()
+	if err != nil {
+		return nil, err
+	}
+	inv := &Inventory{Module: s.modulePath, Interfaces: map[string]map[string]bool{}}
+	for importPath, pkg := range pkgs {
+		for _, f := range pkg.files {
+			s.collectInterfaces(inv, importPath, f)
+		}
+	}
+	for importPath, pkg := range pkgs {
+		for _, f := range pkg.files {
+			s.collectDependencies(inv, pkgs, importPath, f)
+		}
+	}
+	sort.Slice(inv.Dependencies, func(i, j int) bool {
+		a, b := inv.Dependencies[i], inv.Dependencies[j]
+		if a.Interface != b.Interface {
+			return a.Interface.String() < b.Interface.String()
+		}
+		return a.Pos < b.Pos
+	})
+	return inv, nil
+}
+
+// parse reads the module tree into packages keyed by import path.
+func (s *Scanner) parse() (map[string]*scannedPackage, error) {
+	pkgs := map[string]*scannedPackage{}
+	err := filepath.WalkDir(s.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return s.skipDir(p, d)
+		}
+		if !strings.HasSuffix(d.Name(), ".go") || strings.HasSuffix(d.Name(), "_test.go") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+		src, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("read source: %w", err)
+		}
+		mode := parser.ParseComments | parser.SkipObjectResolution
+		f, err := parser.ParseFile(s.fset, filepath.ToSlash(rel), src, mode)
+		if err != nil {
+			return fmt.Errorf("parse source: %w", err)
+		}
+		if ast.IsGenerated(f) || f.Name.Name == "main" {
+			return nil
+		}
+		importPath := s.modulePath
+		if dir := path.Dir(filepath.ToSlash(rel)); dir != "." {
+			importPath += "/" + dir
+		}
+		pkg, ok := pkgs[importPath]
+		if !ok {
+			pkg = &scannedPackage{name: f.Name.Name}
+			pkgs[importPath] = pkg
+		}
+		pkg.files = append(pkg.files, f)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan module: %w", err)
+	}
+	return pkgs, nil
+}
+
+func (s *Scanner) skipDir(p string, d fs.DirEntry) error {
+	if p == s.dir {
+		return nil
+	}
+	name := d.Name()
+	if name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+		return filepath.SkipDir
+	}
+	if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+		return filepath.SkipDir
+	}
+	return nil
+}
+
+func (s *Scanner) collectInterfaces(inv *Inventory, importPath string, f *ast.File) {
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if _, isInterface := ts.Type.(*ast.InterfaceType); !isInterface || !ts.Name.IsExported() {
+				continue
+			}
+			if inv.Interfaces[importPath] == nil {
+				inv.Interfaces[importPath] = map[string]bool{}
+			}
+			inv.Interfaces[importPath][ts.Name.Name] = true
+		}
+	}
+}
+
+func (s *Scanner) collectDependencies(
+	inv *Inventory,
+	pkgs map[string]*scannedPackage,
+	importPath string,
+	f *ast.File,
+) {
+	imports := s.imports(pkgs, f)
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || !fn.Name.IsExported() || !strings.HasPrefix(fn.Name.Name, "New") {
+			continue
+		}
+		for _, param := range fn.Type.Params.List {
+			i, ok := s.resolve(param.Type, importPath, imports)
+			if !ok || !inv.Declares(i) {
+				continue
+			}
+			pos := s.fset.Position(param.Type.Pos())
+			inv.Dependencies = append(inv.Dependencies, Dependency{
+				Interface:   i,
+				Constructor: f.Name.Name + "." + fn.Name.Name,
+				Pos:         pos.Filename + ":" + strconv.Itoa(pos.Line),
+			})
+		}
+	}
+}
+
+// imports maps the names a file uses for module packages onto their import paths.
+func (s *Scanner) imports(pkgs map[string]*scannedPackage, f *ast.File) map[string]string {
+	imports := map[string]string{}
+	for _, spec := range f.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		pkg, ok := pkgs[importPath]
+		if !ok {
+			continue
+		}
+		name := pkg.name
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = importPath
+	}
+	return imports
+}
+
+// resolve returns the interface a parameter type names: Name and Name[T] in the declaring package, pkg.Name
+// and pkg.Name[T] in an imported module package. Pointers, slices, and other composite types name none.
+func (s *Scanner) resolve(expr ast.Expr, importPath string, imports map[string]string) (Interface, bool) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return Interface{Package: importPath, Name: t.Name}, true
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		if !ok || imports[pkg.Name] == "" {
+			return Interface{}, false
+		}
+		return Interface{Package: imports[pkg.Name], Name: t.Sel.Name}, true
+	case *ast.IndexExpr:
+		return s.resolve(t.X, importPath, imports)
+	case *ast.IndexListExpr:
+		return s.resolve(t.X, importPath, imports)
+	default:
+		return Interface{}, false
+	}
+}
@@ -0,0 +1,112 @@
+package mockery_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/cristiano-pacheco/ai-rules/internal/mockery"
+)
+
+func TestScanner_Scan_Modules_ResolvesConstructorInterfaces(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		want     map[string]map[string]bool
+		wantDeps []mockery.Dependency
+	}{
+		{
+			name: "interfaces across packages",
+			files: map[string]string{
+				"repo/repo.go": "package repo\n\ntype UserRepository interface{ Find(id int) error }\n\n" +
+					"type Cache struct{}\n",
+				"user/user.go": "package user\n\nimport r \"example.com/m/repo\"\n\ntype Clock interface{ Now() int }\n\n" +
+					"type UseCase struct{}\n\nfunc NewUseCase(repo r.UserRepository, clock Clock, cache r.Cache) " +
+					"*UseCase {\n\treturn nil\n}\n",
+			},
+			want: map[string]map[string]bool{
+				"example.com/m/repo": {"UserRepository": true},
+				"example.com/m/user": {"Clock": true},
+			},
+			wantDeps: []mockery.Dependency{
+				{
+					Interface:   mockery.Interface{Package: "example.com/m/repo", Name: "UserRepository"},
+					Constructor: "user.NewUseCase",
+					Pos:         "user/user.go:9",
+				},
+				{
+					Interface:   mockery.Interface{Package: "example.com/m/user", Name: "Clock"},
+					Constructor: "user.NewUseCase",
+					Pos:         "user/user.go:9",
+				},
+			},
+		},
+		{
+			name: "generic interfaces",
+			files: map[string]string{
+				"store/store.go": "package store\n\ntype Store[K comparable, V any] interface{ Get(k K) V }\n\n" +
+					"type Lister[T any] interface{ List() []T }\n",
+				"svc/svc.go": "package svc\n\nimport \"example.com/m/store\"\n\n" +
+					"func NewService(s store.Store[string, int], l store.Lister[int]) int {\n\treturn 0\n}\n",
+			},
+			want: map[string]map[string]bool{"example.com/m/store": {"Store": true, "Lister": true}},
+			wantDeps: []mockery.Dependency{
+				{
+					Interface:   mockery.Interface{Package: "example.com/m/store", Name: "Lister"},
+					Constructor: "svc.NewService",
+					Pos:         "svc/svc.go:5",
+				},
+				{
+					Interface:   mockery.Interface{Package: "example.com/m/store", Name: "Store"},
+					Constructor: "svc.NewService",
+					Pos:         "svc/svc.go:5",
+				},
+			},
+		},
+		{
+			name: "nested module, testdata, tests, and main packages",
+			files: map[string]string{
+				"tools/go.mod":        "module example.com/m/tools\n\ngo 1.24\n",
+				"tools/tool.go":       "package tools\n\ntype Tool interface{ Run() }\n",
+				"testdata/fixture.go": "package fixture\n\ntype Fixture interface{ Load() }\n",
+				"cmd/app/main.go":     "package main\n\ntype Command interface{ Exec() }\n",
+				"app/app.go":          "package app\n\ntype Sender interface{ Send() }\n",
+				"app/app_test.go":     "package app\n\ntype Recorder interface{ Record() }\n",
+				"app/mocks_generated.go": "// Code generated by mockery. DO NOT EDIT.\n\npackage app\n\n" +
+					"type Generated interface{ Do() }\n",
+			},
+			want: map[string]map[string]bool{"example.com/m/app": {"Sender": true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			tt.files["go.mod"] = "module example.com/m\n\ngo 1.24\n"
+			for name, content := range tt.files {
+				path := filepath.Join(dir, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Act
+			inv, err := mockery.NewScanner("example.com/m", dir).Scan()
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			if !reflect.DeepEqual(inv.Interfaces, tt.want) {
+				t.Errorf("Interfaces = %v, want %v", inv.Interfaces, tt.want)
+			}
+			if !reflect.DeepEqual(inv.Dependencies, tt.wantDeps) {
+				t.Errorf("Dependencies = %+v, want %+v", inv.Dependencies, tt.wantDeps)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+// Package airules embeds the skills so the ai-rules CLI can install them into a project.
+package airules
+
+import "embed"
+
+// Skills holds the skills directory: one <name>/SKILL.md per skill, plus the files next to it.
+//
+//go:embed skills
+var Skills embed.FS